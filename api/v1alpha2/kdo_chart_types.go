@@ -116,12 +116,35 @@ type Operation struct {
 	Progress int `json:"progress"`
 }
 
+// HealthStatus defines the status of a chart as returned by its status method
+type HealthStatus struct {
+	// Name of the chart
+	Name string `json:"name"`
+	// Health of the chart, e.g. ready, progressing or missing
+	Health string `json:"health"`
+	// +optional
+	// Endpoints provided by the chart
+	Endpoints []string `json:"endpoints,omitempty"`
+	// +optional
+	// Messages describing the status of the chart
+	Messages []string `json:"messages,omitempty"`
+}
+
 // ChartStatus defines the observed state of KdoChart
 type ChartStatus struct {
 
 	// LastOp containts the last operation status
 	// +optional
 	LastOp Operation `json:"lastOp,omitempty"`
+	// Health is ready if the chart and all its subcharts are ready
+	// +optional
+	Health string `json:"health,omitempty"`
+	// Charts contains the status of the chart and its subcharts
+	// +optional
+	Charts []HealthStatus `json:"charts,omitempty"`
+	// LastStatusUpdate is the time of the last update of health and charts
+	// +optional
+	LastStatusUpdate *metav1.Time `json:"lastStatusUpdate,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -130,6 +153,7 @@ type ChartStatus struct {
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.status.lastOp.type`
 // +kubebuilder:printcolumn:name="Progress",type=integer,JSONPath=`.status.lastOp.progress`
+// +kubebuilder:printcolumn:name="Health",type=string,JSONPath=`.status.health`
 
 // KdoChart is the Schema for the kdocharts API
 type KdoChart struct {
@@ -2,6 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
@@ -12,6 +15,7 @@ import (
 
 var applyChartArgs = kdo.ChartOptions{}
 var applyK8sArgs = k8s.Configs{}
+var applyReuseValues bool
 
 var newK8s = func(configs ...k8s.Config) (k8s.K8s, error) {
 	return k8s.NewK8s(configs...)
@@ -29,25 +33,46 @@ var applyCmd = &cobra.Command{
 		if err != nil {
 			exit(err)
 		}
-		exit(apply(args[0], k8s, applyChartArgs.Merge()))
+		ctx, cancel := commandContext()
+		k8s = k8s.WithContext(ctx)
+		opts := []kdo.ChartOption{applyChartArgs.Merge()}
+		if applyReuseValues {
+			opts = append(opts, kdo.WithReuseValues(k8s))
+		}
+		err = apply(args[0], k8s, os.Stdout, opts...)
+		cancel()
+		exit(err)
 	},
 }
 
-func apply(url string, k k8s.K8s, opts ...kdo.ChartOption) error {
+func apply(url string, k k8s.K8s, writer io.Writer, opts ...kdo.ChartOption) error {
 	repo, err := repo()
 	if err != nil {
 		return err
 	}
 	thread := &starlark.Thread{Name: "main", Load: rootExecuteOptions.load}
+	kdo.SetContext(thread, k.Context())
 	c, err := repo.Get(thread, url, opts...)
 	if err != nil {
 		return err
 	}
-	return c.Apply(thread, k)
+	if err := c.Apply(thread, k); err != nil {
+		return err
+	}
+	notes, err := c.Notes(thread, k)
+	if err != nil {
+		return err
+	}
+	for _, n := range notes {
+		fmt.Fprintf(writer, "\nNOTES of %s:\n%s\n", n.Name, strings.TrimRight(n.Notes, "\n"))
+	}
+	return nil
 }
 
 func init() {
 	applyChartArgs.AddFlags(applyCmd.Flags())
+	applyChartArgs.AddApplyFlags(applyCmd.Flags())
 	applyK8sArgs.AddFlags(applyCmd.Flags())
 	rootOsbConfig.AddFlags(applyCmd.Flags())
+	applyCmd.Flags().BoolVar(&applyReuseValues, "reuse-values", false, "Reuse the values of the last apply, which aren't given explicitly")
 }
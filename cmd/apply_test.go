@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"io/ioutil"
 	"path"
 	"path/filepath"
 	"runtime"
@@ -40,7 +41,7 @@ var _ = Describe("Apply Chart", func() {
 			return &k8s.Object{}, nil
 		}
 
-		err := apply(path.Join(example, "cf"), k, kdo.WithNamespace("mynamespace"))
+		err := apply(path.Join(example, "cf"), k, ioutil.Discard, kdo.WithNamespace("mynamespace"))
 		Expect(err).ToNot(HaveOccurred())
 		output := writer.String()
 		Expect(output).To(ContainSubstring("CREATE OR REPLACE USER 'uaa'"))
@@ -61,7 +62,7 @@ var _ = Describe("Apply Chart", func() {
 	It("produces correct objects", func() {
 		Skip("unsupported")
 		k := k8s.NewK8sInMemory("default")
-		err := apply(path.Join(example, "cf"), k, kdo.WithNamespace("mynamespace"))
+		err := apply(path.Join(example, "cf"), k, ioutil.Discard, kdo.WithNamespace("mynamespace"))
 		Expect(err).ToNot(HaveOccurred())
 		uaa := k.ForSubChart("uaa", "uaa", &semver.Version{}, 0).(*k8s.K8sInMemory)
 		_, err = uaa.GetObject("secret", "uaa-secret", nil)
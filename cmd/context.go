@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+var rootTimeout time.Duration
+
+// commandContext returns the context of a command. It is cancelled after --timeout or if the process is interrupted.
+func commandContext() (context.Context, context.CancelFunc) {
+	ctx, cancelTimeout := context.Background(), context.CancelFunc(func() {})
+	if rootTimeout > 0 {
+		ctx, cancelTimeout = context.WithTimeout(ctx, rootTimeout)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+		cancelTimeout()
+	}
+}
@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"os"
+	"time"
+
 	kdov1a2 "github.com/sap/kubernetes-deployment-orchestrator/api/v1alpha2"
 	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo"
 
 	"github.com/pkg/errors"
 	"github.com/sap/kubernetes-deployment-orchestrator/controllers"
@@ -26,16 +30,23 @@ var controllerCmd = &cobra.Command{
 	},
 }
 var (
-	setupLog      = ctrl.Log.WithName("setup")
-	reconcilerLog = ctrl.Log.WithName("reconciler")
-	options       = control.Options{MaxConcurrentReconciles: 3}
+	setupLog       = ctrl.Log.WithName("setup")
+	reconcilerLog  = ctrl.Log.WithName("reconciler")
+	options        = control.Options{MaxConcurrentReconciles: 3}
+	statusInterval = time.Minute
+	reuseValues    = true
+	encryptionKey  = os.Getenv("KDO_ENCRYPTION_KEY")
 )
 
 func controller(stopCh <-chan struct{}) error {
 
 	ctrl.SetLogger(zap.Logger(true))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{})
+	config := ctrl.GetConfigOrDie()
+	if err := controllerK8sArgs.ConfigureRest(config); err != nil {
+		return err
+	}
+	mgr, err := ctrl.NewManager(config, ctrl.Options{})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		return err
@@ -50,6 +61,13 @@ func controller(stopCh <-chan struct{}) error {
 		return err
 	}
 
+	var keyProvider kdo.KeyProvider
+	if encryptionKey != "" {
+		if keyProvider, err = kdo.NewKeyProvider(encryptionKey); err != nil {
+			return err
+		}
+	}
+
 	reconciler := &controllers.KdoChartReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -59,14 +77,23 @@ func controller(stopCh <-chan struct{}) error {
 			configs = append([]k8s.Config{controllerK8sArgs.Merge()}, configs...)
 			return k8s.NewK8s(configs...)
 		},
-		Load:     rootExecuteOptions.load,
-		Recorder: mgr.GetEventRecorderFor("kdochart-controller"),
+		Load:        rootExecuteOptions.load,
+		Recorder:    mgr.GetEventRecorderFor("kdochart-controller"),
+		Timeout:     rootTimeout,
+		ReuseValues: reuseValues,
+		KeyProvider: keyProvider,
 	}
 	err = reconciler.SetupWithManager(mgr, options)
 	if err != nil {
 		return errors.Wrap(err, "unable to create controller")
 	}
 
+	if statusInterval > 0 {
+		if err := mgr.Add(reconciler.StatusUpdater(statusInterval)); err != nil {
+			return errors.Wrap(err, "unable to add status updater")
+		}
+	}
+
 	err = ctrl.NewWebhookManagedBy(mgr).
 		For(&kdov1a2.KdoChart{}).
 		Complete()
@@ -83,5 +110,8 @@ func controller(stopCh <-chan struct{}) error {
 
 func init() {
 	controllerCmd.Flags().IntVar(&options.MaxConcurrentReconciles, "concurrent-reconciles", options.MaxConcurrentReconciles, "Number of concurrent reconciles")
+	controllerCmd.Flags().DurationVar(&statusInterval, "status-interval", statusInterval, "Interval for updating the status of applied KdoCharts, zero disables status updates")
+	controllerCmd.Flags().BoolVar(&reuseValues, "reuse-values", reuseValues, "Reuse the values of the last apply, which aren't given in the KdoChart")
+	controllerCmd.Flags().StringVar(&encryptionKey, "encryption-key", encryptionKey, "Encrypt the values stored in the cluster with this key (file://<path>, age://<path> or kms://<path>)")
 	controllerK8sArgs.AddFlags(controllerCmd.Flags())
 }
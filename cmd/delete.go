@@ -26,7 +26,10 @@ var deleteCmd = &cobra.Command{
 		if err != nil {
 			exit(err)
 		}
-		exit(delete(args[0], k8s, &deleteOptions, deleteChartArgs.Merge()))
+		ctx, cancel := commandContext()
+		err = delete(args[0], k8s.WithContext(ctx), &deleteOptions, deleteChartArgs.Merge())
+		cancel()
+		exit(err)
 	},
 }
 
@@ -36,6 +39,7 @@ func delete(url string, k k8s.K8s, deleteOpt *kdo.DeleteOptions, opts ...kdo.Cha
 		return err
 	}
 	thread := &starlark.Thread{Name: "main", Load: rootExecuteOptions.load}
+	kdo.SetContext(thread, k.Context())
 	c, err := repo.Get(thread, url, opts...)
 	if err != nil {
 		return err
@@ -45,6 +49,7 @@ func delete(url string, k k8s.K8s, deleteOpt *kdo.DeleteOptions, opts ...kdo.Cha
 
 func init() {
 	deleteChartArgs.AddFlags(deleteCmd.Flags())
+	deleteChartArgs.AddParallelismFlag(deleteCmd.Flags())
 	deleteK8sArgs.AddFlags(deleteCmd.Flags())
 	rootOsbConfig.AddFlags(deleteCmd.Flags())
 	deleteOptions.AddFlags(deleteCmd.Flags())
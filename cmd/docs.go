@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo"
+
+	"github.com/spf13/cobra"
+)
+
+var docsChartArgs = kdo.ChartOptions{}
+var docsOut string
+var docsCheck bool
+
+var docsCmd = &cobra.Command{
+	Use:   "docs [chart]",
+	Short: "generate the reference documentation of a kdo chart",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(docs(args[0], docsOut, docsCheck))
+	},
+}
+
+func docs(url string, out string, check bool) error {
+	repo, err := repo()
+	if err != nil {
+		return err
+	}
+	thread := &starlark.Thread{Name: "main", Load: rootExecuteOptions.load}
+	c, err := repo.Get(thread, url, docsChartArgs.Merge(), kdo.WithPlaceholderArgs(true))
+	if err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	if err := c.Info().Markdown(buf); err != nil {
+		return err
+	}
+	if out == "" {
+		if check {
+			return fmt.Errorf("--check requires --out")
+		}
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if check {
+		existing, err := ioutil.ReadFile(out)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if !bytes.Equal(existing, buf.Bytes()) {
+			return fmt.Errorf("%s is out of date, run kdo docs %s --out %s", out, url, out)
+		}
+		return nil
+	}
+	return ioutil.WriteFile(out, buf.Bytes(), 0644)
+}
+
+func init() {
+	docsChartArgs.AddFlags(docsCmd.Flags())
+	docsCmd.Flags().StringVar(&docsOut, "out", "", "File to write the documentation to (default stdout)")
+	docsCmd.Flags().BoolVar(&docsCheck, "check", false, "Fail if the file given with --out isn't up to date instead of writing it")
+}
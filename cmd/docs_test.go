@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Docs", func() {
+
+	It("writes and checks the documentation", func() {
+		dir, err := ioutil.TempDir("", "kdo")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		out := path.Join(dir, "README.md")
+		chart := path.Join(example, "hello")
+		Expect(docs(chart, out, true)).To(MatchError(ContainSubstring("is out of date")))
+		Expect(docs(chart, out, false)).To(Succeed())
+		data, err := ioutil.ReadFile(out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("# hello"))
+		Expect(docs(chart, out, true)).To(Succeed())
+	})
+
+	It("documents charts with required parameters of init", func() {
+		dir, err := ioutil.TempDir("", "kdo")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		Expect(ioutil.WriteFile(path.Join(dir, "Chart.star"), []byte("def init(self, name, size=3):\n\tself.size = property(default=size)\n"), 0644)).To(Succeed())
+		out := path.Join(dir, "README.md")
+		Expect(docs(dir, out, false)).To(Succeed())
+		data, err := ioutil.ReadFile(out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("name"))
+	})
+})
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/starutils"
+	"gopkg.in/yaml.v2"
+
+	"github.com/spf13/cobra"
+)
+
+var getK8sArgs = k8s.Configs{}
+var getNamespace string
+var getValuesOutput string
+var getEncryptionKey string
+var getSuffix string
+
+var getCmd = &cobra.Command{
+	Use:   "get",
+	Short: "get information about installed kdo charts",
+	Long:  ``,
+}
+
+var getValuesCmd = &cobra.Command{
+	Use:   "values [genus]",
+	Short: "print the values of an installed kdo chart",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		k8s, err := newK8s(getK8sArgs.Merge())
+		if err != nil {
+			exit(err)
+		}
+		ctx, cancel := commandContext()
+		err = getValues(k8s.WithContext(ctx), getNamespace, args[0], getValuesOutput, os.Stdout)
+		cancel()
+		exit(err)
+	},
+}
+
+var getManifestCmd = &cobra.Command{
+	Use:   "manifest [genus]",
+	Short: "render the manifest of an installed kdo chart with the values of the last apply",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		k8s, err := newK8s(getK8sArgs.Merge())
+		if err != nil {
+			exit(err)
+		}
+		ctx, cancel := commandContext()
+		err = getManifest(k8s.WithContext(ctx), getNamespace, args[0], os.Stdout)
+		cancel()
+		exit(err)
+	},
+}
+
+func getInstalled(thread *starlark.Thread, k k8s.K8s, namespace string, genus string) (kdo.ChartValue, error) {
+	repo, err := repo()
+	if err != nil {
+		return nil, err
+	}
+	kdo.SetContext(thread, k.Context())
+	options := []kdo.ChartOption{}
+	if getEncryptionKey != "" {
+		provider, err := kdo.NewKeyProvider(getEncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, kdo.WithEncryption(provider))
+	}
+	return repo.GetInstalled(thread, k, namespace, genus, getSuffix, options...)
+}
+
+func getValues(k k8s.K8s, namespace string, genus string, output string, writer io.Writer) error {
+	if output != "yaml" && output != "json" {
+		return fmt.Errorf("invalid output format %s, must be yaml or json", output)
+	}
+	thread := &starlark.Thread{Name: "main", Load: rootExecuteOptions.load}
+	c, err := getInstalled(thread, k, namespace, genus)
+	if err != nil {
+		return err
+	}
+	values := starutils.ToGo(c.GetValueOrDefault())
+	if output == "json" {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(values)
+	}
+	return yaml.NewEncoder(writer).Encode(values)
+}
+
+func getManifest(k k8s.K8s, namespace string, genus string, writer io.Writer) error {
+	thread := &starlark.Thread{Name: "main", Load: rootExecuteOptions.load}
+	c, err := getInstalled(thread, k, namespace, genus)
+	if err != nil {
+		return err
+	}
+	return c.Template(thread, k)(writer)
+}
+
+func init() {
+	defaultNamespace := os.Getenv("KDO_NAMESPACE")
+	if defaultNamespace == "" {
+		defaultNamespace = "default"
+	}
+	getCmd.PersistentFlags().StringVarP(&getNamespace, "namespace", "n", defaultNamespace, "namespace of the installed chart")
+	getCmd.PersistentFlags().StringVarP(&getSuffix, "suffix", "s", "", "suffix of the installed chart")
+	getCmd.PersistentFlags().StringVar(&getEncryptionKey, "encryption-key", os.Getenv("KDO_ENCRYPTION_KEY"), "Decrypt the values stored in the cluster with this key")
+	getK8sArgs.AddFlags(getCmd.PersistentFlags())
+	getValuesCmd.Flags().StringVarP(&getValuesOutput, "output", "o", "yaml", "Output format, yaml or json")
+	getCmd.AddCommand(getValuesCmd)
+	getCmd.AddCommand(getManifestCmd)
+}
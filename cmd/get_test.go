@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path"
+
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Get", func() {
+
+	It("gets values and manifest of an installed chart", func() {
+		k := k8s.NewK8sInMemory("test")
+		writer := &bytes.Buffer{}
+		Expect(getValues(k, "test", "hello", "yaml", writer)).To(MatchError("chart hello isn't installed in namespace test"))
+		Expect(apply(path.Join(example, "hello"), k, ioutil.Discard, kdo.WithNamespace("test"), kdo.WithValues(map[string]interface{}{"message": "deployed"}))).To(Succeed())
+
+		Expect(getValues(k, "test", "hello", "yaml", writer)).To(Succeed())
+		Expect(writer.String()).To(ContainSubstring("message: deployed"))
+		writer.Reset()
+		Expect(getValues(k, "test", "hello", "json", writer)).To(Succeed())
+		Expect(writer.String()).To(ContainSubstring(`"message": "deployed"`))
+		Expect(getValues(k, "test", "hello", "xml", writer)).To(HaveOccurred())
+
+		writer.Reset()
+		Expect(getManifest(k, "test", "hello", writer)).To(Succeed())
+		Expect(writer.String()).To(ContainSubstring("kind: Secret"))
+		Expect(writer.String()).To(ContainSubstring("ZGVwbG95ZWQ=")) // base64 of deployed
+	})
+})
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo"
+
+	"github.com/spf13/cobra"
+)
+
+var imagesChartArgs = kdo.ChartOptions{}
+var imagesOutput string
+
+var imagesCmd = &cobra.Command{
+	Use:   "images [chart]",
+	Short: "list the container images, versions and sources of a kdo chart and its subcharts",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(images(args[0], imagesOutput, os.Stdout))
+	},
+}
+
+func images(url string, output string, writer io.Writer) error {
+	repo, err := repo()
+	if err != nil {
+		return err
+	}
+	k := k8s.NewK8sInMemoryEmpty()
+	thread := &starlark.Thread{Name: "main", Load: rootExecuteOptions.load}
+	c, err := repo.Get(thread, url, imagesChartArgs.Merge())
+	if err != nil {
+		return err
+	}
+	inventory, err := c.Inventory(thread, k)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	switch output {
+	case "table":
+		w := tabwriter.NewWriter(writer, 3, 4, 1, ' ', 0)
+		defer w.Flush()
+		fmt.Fprintln(w, "CHART\tVERSION\tSOURCE")
+		showInventoryCharts(w, inventory, "")
+		fmt.Fprintln(w, "\nIMAGE\tCHART\tWORKLOAD\tCONTAINER")
+		for _, image := range inventory.AllImages() {
+			fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s\n", image.Image, image.Chart, strings.ToLower(image.Kind), image.Name, image.Container)
+		}
+		return nil
+	case "list":
+		for _, image := range uniqueImages(inventory.AllImages()) {
+			fmt.Fprintln(writer, image)
+		}
+		return nil
+	case "json":
+		return encoder.Encode(inventory)
+	case "cyclonedx":
+		return encoder.Encode(inventory.CycloneDX())
+	case "spdx":
+		return encoder.Encode(inventory.SPDX())
+	}
+	return fmt.Errorf("invalid output format %s, must be table, list, json, cyclonedx or spdx", output)
+}
+
+func showInventoryCharts(w io.Writer, inventory *kdo.ChartInventory, indent string) {
+	fmt.Fprintf(w, "%s%s\t%s\t%s\n", indent, inventory.Name, inventory.Version, inventory.URL)
+	for _, subChart := range inventory.Subcharts {
+		showInventoryCharts(w, subChart, indent+"  ")
+	}
+}
+
+func uniqueImages(chartImages []kdo.ChartImage) []string {
+	unique := map[string]bool{}
+	for _, image := range chartImages {
+		unique[image.Image] = true
+	}
+	result := make([]string, 0, len(unique))
+	for image := range unique {
+		result = append(result, image)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func init() {
+	imagesChartArgs.AddFlags(imagesCmd.Flags())
+	imagesChartArgs.AddRenderFlags(imagesCmd.Flags())
+	imagesCmd.Flags().StringVarP(&imagesOutput, "output", "o", "table", "Output format: table, list, json, cyclonedx or spdx")
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Images", func() {
+
+	It("lists the images of a chart", func() {
+		dir := NewTestDir()
+		defer dir.Remove()
+		dir.MkdirAll("templates", 0755)
+		dir.WriteFile("Chart.yaml", []byte("name: app\nversion: 1.0.0\n"), 0644)
+		dir.WriteFile("templates/pods.yaml", []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: nginx
+    image: nginx:1.19
+  - name: sidecar
+    image: busybox
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: other
+spec:
+  containers:
+  - name: nginx
+    image: nginx:1.19
+`), 0644)
+		writer := &bytes.Buffer{}
+		Expect(images(dir.Root(), "list", writer)).To(Succeed())
+		Expect(writer.String()).To(Equal("busybox\nnginx:1.19\n"))
+		writer.Reset()
+		Expect(images(dir.Root(), "table", writer)).To(Succeed())
+		Expect(writer.String()).To(MatchRegexp(`app +1.0.0`))
+		Expect(writer.String()).To(MatchRegexp(`busybox +app +pod/web +sidecar`))
+		writer.Reset()
+		Expect(images(dir.Root(), "spdx", writer)).To(Succeed())
+		Expect(writer.String()).To(ContainSubstring(`"spdxVersion": "SPDX-2.3"`))
+		Expect(images(dir.Root(), "xml", writer)).To(HaveOccurred())
+	})
+})
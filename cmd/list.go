@@ -40,9 +40,9 @@ func list(k k8s.K8s, listOptions *kdo.RepoListOptions) error {
 	}
 	writer := tabwriter.NewWriter(os.Stdout, 3, 4, 1, ' ', 0)
 	defer writer.Flush()
-	writer.Write([]byte("GENUS\tNAMESPACE\tVERSION\n"))
+	writer.Write([]byte("GENUS\tSUFFIX\tNAMESPACE\tVERSION\n"))
 	for _, c := range charts {
-		writer.Write([]byte(c.GetGenus() + "\t" + c.GetNamespace() + "\t" + c.GetVersion().String() + "\n"))
+		writer.Write([]byte(c.GetGenus() + "\t" + c.GetSuffix() + "\t" + c.GetNamespace() + "\t" + c.GetVersion().String() + "\n"))
 	}
 	return nil
 }
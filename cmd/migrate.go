@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateK8sArgs = k8s.Configs{}
+var migrateNamespace string
+var migrateSuffix string
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [genus]",
+	Short: "migrate a kdo chart installed with a suffix to a separate instance named kdo.<genus>-<suffix>",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		k8s, err := newK8s(migrateK8sArgs.Merge())
+		if err != nil {
+			exit(err)
+		}
+		ctx, cancel := commandContext()
+		err = migrate(k8s.WithContext(ctx), migrateNamespace, args[0], migrateSuffix, os.Stdout)
+		cancel()
+		exit(err)
+	},
+}
+
+func migrate(k k8s.K8s, namespace string, genus string, suffix string, writer io.Writer) error {
+	repo, err := repo()
+	if err != nil {
+		return err
+	}
+	thread := &starlark.Thread{Name: "main", Load: rootExecuteOptions.load}
+	kdo.SetContext(thread, k.Context())
+	return kdo.MigrateInstance(thread, repo, k, namespace, genus, suffix, writer)
+}
+
+func init() {
+	defaultNamespace := os.Getenv("KDO_NAMESPACE")
+	if defaultNamespace == "" {
+		defaultNamespace = "default"
+	}
+	migrateCmd.Flags().StringVarP(&migrateNamespace, "namespace", "n", defaultNamespace, "namespace of the installed chart")
+	migrateCmd.Flags().StringVarP(&migrateSuffix, "suffix", "s", "", "suffix the chart was installed with")
+	migrateK8sArgs.AddFlags(migrateCmd.Flags())
+}
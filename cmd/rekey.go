@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo"
+
+	"github.com/spf13/cobra"
+)
+
+var rekeyOptions = &kdo.RekeyOptions{}
+var rekeyK8sArgs = &k8s.Configs{}
+
+var rekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "re-encrypt the values of installed kdo charts with a new encryption key",
+	Long:  ``,
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		k8s, err := newK8s(rekeyK8sArgs.Merge())
+		if err != nil {
+			exit(err)
+		}
+		exit(kdo.Rekey(k8s, rekeyOptions, os.Stdout))
+	},
+}
+
+func init() {
+	rekeyOptions.AddFlags(rekeyCmd.Flags())
+	rekeyK8sArgs.AddFlags(rekeyCmd.Flags())
+}
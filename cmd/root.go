@@ -35,7 +35,16 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(testCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(docsCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(getCmd)
+	rootCmd.AddCommand(rekeyCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(imagesCmd)
 	rootCmd.PersistentFlags().StringVar(&repoConfigFile, "config", repoConfigFileDefault, "kdo configuration file (e.g. credentials)")
+	rootCmd.PersistentFlags().DurationVar(&rootTimeout, "timeout", 0, "Timeout for the whole operation, e.g. 30m (0 means no timeout)")
 }
 
 func repo() (kdo.Repo, error) {
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo"
+
+	"github.com/spf13/cobra"
+)
+
+var schemaChartArgs = kdo.ChartOptions{}
+var schemaStructural bool
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [chart]",
+	Short: "print the JSON schema of the values of a kdo chart",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(schema(args[0], os.Stdout))
+	},
+}
+
+func schema(url string, writer io.Writer) error {
+	repo, err := repo()
+	if err != nil {
+		return err
+	}
+	thread := &starlark.Thread{Name: "main", Load: rootExecuteOptions.load}
+	c, err := repo.Get(thread, url, schemaChartArgs.Merge(), kdo.WithPlaceholderArgs(true))
+	if err != nil {
+		return err
+	}
+	s := c.Schema()
+	if schemaStructural {
+		s = s.Structural()
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+func init() {
+	schemaChartArgs.AddFlags(schemaCmd.Flags())
+	schemaCmd.Flags().BoolVar(&schemaStructural, "crd", false, "print a structural schema usable for the values of the KdoChart custom resource")
+}
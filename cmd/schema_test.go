@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Schema", func() {
+
+	It("prints the schema of a chart", func() {
+		writer := &bytes.Buffer{}
+		Expect(schema(path.Join(example, "hello"), writer)).To(Succeed())
+		var s map[string]interface{}
+		Expect(json.Unmarshal(writer.Bytes(), &s)).To(Succeed())
+		Expect(s["title"]).To(Equal("hello"))
+		Expect(s["properties"]).To(HaveKeyWithValue("message", map[string]interface{}{"default": "Hello World"}))
+		Expect(s["properties"]).To(HaveKeyWithValue("arg", map[string]interface{}{"default": "test"}))
+	})
+
+	It("prints the schema of charts with required parameters of init", func() {
+		dir, err := ioutil.TempDir("", "kdo")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		Expect(ioutil.WriteFile(path.Join(dir, "Chart.star"), []byte("def init(self, name, size=3):\n\tself.size = property(default=size)\n"), 0644)).To(Succeed())
+		writer := &bytes.Buffer{}
+		Expect(schema(dir, writer)).To(Succeed())
+		var s map[string]interface{}
+		Expect(json.Unmarshal(writer.Bytes(), &s)).To(Succeed())
+		Expect(s["required"]).To(Equal([]interface{}{"name"}))
+	})
+})
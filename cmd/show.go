@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo"
+
+	"github.com/spf13/cobra"
+)
+
+var showChartArgs = kdo.ChartOptions{}
+var showOutput string
+
+var showCmd = &cobra.Command{
+	Use:   "show [chart]",
+	Short: "show the parameters, properties, methods, subcharts and dependencies of a kdo chart",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(show(args[0], showOutput, os.Stdout))
+	},
+}
+
+func show(url string, output string, writer io.Writer) error {
+	repo, err := repo()
+	if err != nil {
+		return err
+	}
+	thread := &starlark.Thread{Name: "main", Load: rootExecuteOptions.load}
+	c, err := repo.Get(thread, url, showChartArgs.Merge(), kdo.WithPlaceholderArgs(true))
+	if err != nil {
+		return err
+	}
+	switch output {
+	case "json":
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(c.Info())
+	case "text":
+		w := tabwriter.NewWriter(writer, 3, 4, 1, ' ', 0)
+		defer w.Flush()
+		showChart(w, c.Info(), "")
+		return nil
+	}
+	return fmt.Errorf("invalid output format %s, must be text or json", output)
+}
+
+func showChart(w io.Writer, info *kdo.ChartInfo, indent string) {
+	fmt.Fprintf(w, "%sName:\t%s\n", indent, info.Name)
+	fmt.Fprintf(w, "%sVersion:\t%s\n", indent, info.Class.Version)
+	if info.Class.AppVersion != "" {
+		fmt.Fprintf(w, "%sApp version:\t%s\n", indent, info.Class.AppVersion)
+	}
+	if info.Class.Description != "" {
+		fmt.Fprintf(w, "%sDescription:\t%s\n", indent, info.Class.Description)
+	}
+	if len(info.Params) != 0 {
+		fmt.Fprintf(w, "%sParameters:\n", indent)
+		for _, p := range info.Params {
+			if p.Required {
+				fmt.Fprintf(w, "%s  %s\t(required)\n", indent, p.Name)
+			} else {
+				fmt.Fprintf(w, "%s  %s\tdefault: %s\n", indent, p.Name, showValue(p.Default))
+			}
+		}
+	}
+	if len(info.Properties) != 0 {
+		fmt.Fprintf(w, "%sProperties:\n", indent)
+		showProperties(w, info.Properties, indent+"  ")
+	}
+	if len(info.Methods) != 0 {
+		fmt.Fprintf(w, "%sMethods:\t%s\n", indent, strings.Join(info.Methods, ", "))
+	}
+	if len(info.Jewels) != 0 {
+		fmt.Fprintf(w, "%sJewels:\n", indent)
+		for _, j := range info.Jewels {
+			fmt.Fprintf(w, "%s  %s\t%s\tsecret: %s\tkeys: %s\n", indent, j.Attribute, j.Type, j.Secret, strings.Join(j.Keys, ", "))
+		}
+	}
+	if len(info.Dependencies) != 0 {
+		fmt.Fprintf(w, "%sDependencies:\n", indent)
+		for _, d := range info.Dependencies {
+			fmt.Fprintf(w, "%s  %s\t%s\t%s\tnamespace: %s\n", indent, d.Attribute, d.URL, d.Constraint, d.Namespace)
+		}
+	}
+	if len(info.Subcharts) != 0 {
+		fmt.Fprintf(w, "%sSubcharts:\n", indent)
+		for _, s := range info.Subcharts {
+			fmt.Fprintf(w, "%s  %s:\n", indent, s.Attribute)
+			subchart := s.ChartInfo
+			showChart(w, &subchart, indent+"    ")
+		}
+	}
+}
+
+func showProperties(w io.Writer, properties []kdo.PropertyInfo, indent string) {
+	for _, p := range properties {
+		details := []string{p.Type}
+		if p.Required {
+			details = append(details, "required")
+		}
+		if p.Default != nil {
+			details = append(details, "default: "+showValue(p.Default))
+		}
+		if len(p.Choices) != 0 {
+			details = append(details, "choices: "+showValue(p.Choices))
+		}
+		if p.Pattern != "" {
+			details = append(details, "pattern: "+p.Pattern)
+		}
+		if p.Min != nil {
+			details = append(details, "min: "+showValue(p.Min))
+		}
+		if p.Max != nil {
+			details = append(details, "max: "+showValue(p.Max))
+		}
+		fmt.Fprintf(w, "%s%s\t%s\t%s\n", indent, p.Name, strings.Join(details, ", "), p.Description)
+		showProperties(w, p.Properties, indent+"  ")
+	}
+}
+
+func showValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func init() {
+	showChartArgs.AddFlags(showCmd.Flags())
+	showCmd.Flags().StringVarP(&showOutput, "output", "o", "text", "Output format (text or json)")
+}
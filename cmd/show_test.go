@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Show", func() {
+
+	It("shows the api of a chart", func() {
+		writer := &bytes.Buffer{}
+		Expect(show(path.Join(example, "hello"), "text", writer)).To(Succeed())
+		Expect(writer.String()).To(ContainSubstring("Description: Hello world"))
+		Expect(writer.String()).To(MatchRegexp(`message +any, default: "Hello World"`))
+		writer.Reset()
+		Expect(show(path.Join(example, "hello"), "json", writer)).To(Succeed())
+		Expect(writer.String()).To(ContainSubstring(`"name": "arg"`))
+		Expect(show(path.Join(example, "hello"), "xml", writer)).To(HaveOccurred())
+	})
+
+	It("shows charts with required parameters of init", func() {
+		dir, err := ioutil.TempDir("", "kdo")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		Expect(ioutil.WriteFile(path.Join(dir, "Chart.star"), []byte("def init(self, name, size=3):\n\tself.size = property(default=size)\n"), 0644)).To(Succeed())
+		writer := &bytes.Buffer{}
+		Expect(show(dir, "text", writer)).To(Succeed())
+		Expect(writer.String()).To(MatchRegexp(`name +\(required\)`))
+		Expect(writer.String()).To(MatchRegexp(`size +any, default: 3`))
+	})
+})
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo"
+
+	"github.com/spf13/cobra"
+)
+
+var statusChartArgs = kdo.ChartOptions{}
+var statusK8sArgs = k8s.Configs{}
+var statusOutput string
+
+var statusCmd = &cobra.Command{
+	Use:   "status [chart]",
+	Short: "show the status of an installed kdo chart and its subcharts",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		k8s, err := newK8s(statusK8sArgs.Merge())
+		if err != nil {
+			exit(err)
+		}
+		ctx, cancel := commandContext()
+		err = status(args[0], k8s.WithContext(ctx), statusOutput, os.Stdout, statusChartArgs.Merge())
+		cancel()
+		exit(err)
+	},
+}
+
+func status(url string, k k8s.K8s, output string, writer io.Writer, opts ...kdo.ChartOption) error {
+	if output != "text" && output != "json" {
+		return fmt.Errorf("invalid output format %s, must be text or json", output)
+	}
+	repo, err := repo()
+	if err != nil {
+		return err
+	}
+	thread := &starlark.Thread{Name: "main", Load: rootExecuteOptions.load}
+	kdo.SetContext(thread, k.Context())
+	c, err := repo.Get(thread, url, opts...)
+	if err != nil {
+		return err
+	}
+	s, err := c.Status(thread, k)
+	if err != nil {
+		return err
+	}
+	if output == "json" {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(s)
+	}
+	printStatus(writer, s, "")
+	return nil
+}
+
+func printStatus(w io.Writer, s *kdo.ChartStatus, indent string) {
+	fmt.Fprintf(w, "%s%s\n", indent, s.String())
+	for i := range s.Subcharts {
+		printStatus(w, &s.Subcharts[i], indent+"  ")
+	}
+}
+
+func init() {
+	statusChartArgs.AddFlags(statusCmd.Flags())
+	statusK8sArgs.AddFlags(statusCmd.Flags())
+	statusCmd.Flags().StringVarP(&statusOutput, "output", "o", "text", "Output format, text or json")
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path"
+
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Status", func() {
+
+	It("shows the status of a chart", func() {
+		k := k8s.NewK8sInMemory("test")
+		writer := &bytes.Buffer{}
+		Expect(status(path.Join(example, "hello"), k, "text", writer, kdo.WithNamespace("test"))).To(Succeed())
+		Expect(writer.String()).To(Equal("hello: missing, secret/secret is missing\n"))
+		Expect(apply(path.Join(example, "hello"), k, ioutil.Discard, kdo.WithNamespace("test"))).To(Succeed())
+		writer.Reset()
+		Expect(status(path.Join(example, "hello"), k, "json", writer, kdo.WithNamespace("test"))).To(Succeed())
+		Expect(writer.String()).To(ContainSubstring(`"health": "ready"`))
+		Expect(status(path.Join(example, "hello"), k, "xml", writer)).To(HaveOccurred())
+	})
+})
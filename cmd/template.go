@@ -27,13 +27,17 @@ var templateCmd = &cobra.Command{
 		if err != nil {
 			exit(err)
 		}
-		exit(template(args[0], k8s)(os.Stdout))
+		ctx, cancel := commandContext()
+		err = template(args[0], k8s.WithContext(ctx))(os.Stdout)
+		cancel()
+		exit(err)
 	},
 }
 
 func template(url string, k k8s.K8s) k8s.Stream {
 
 	thread := &starlark.Thread{Name: "main", Load: rootExecuteOptions.load}
+	kdo.SetContext(thread, k.Context())
 	repo, err := repo()
 	if err != nil {
 		return k8s.ErrorStream(err)
@@ -49,5 +53,6 @@ func template(url string, k k8s.K8s) k8s.Stream {
 
 func init() {
 	templateChartArgs.AddFlags(templateCmd.Flags())
+	templateChartArgs.AddRenderFlags(templateCmd.Flags())
 	templateK8sArgs.AddFlags(templateCmd.Flags())
 }
@@ -29,11 +29,13 @@ import (
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	kdov1a2 "github.com/sap/kubernetes-deployment-orchestrator/api/v1alpha2"
 )
@@ -55,8 +57,16 @@ type KdoChartReconciler struct {
 	K8s      func(configs ...k8s.Config) (k8s.K8s, error)
 	Load     func(thread *starlark.Thread, module string) (dict starlark.StringDict, err error)
 	Recorder record.EventRecorder
+	Timeout  time.Duration
+	// ReuseValues merges the values persisted by the last apply under the values of the spec
+	ReuseValues bool
+	// KeyProvider encrypts the values persisted by apply, values are stored in plain text if nil
+	KeyProvider kdo.KeyProvider
 }
 
+// defaultTimeout limits the duration of one apply or delete if no timeout is configured
+const defaultTimeout = 1 * time.Hour
+
 type kdoChartPredicate struct {
 }
 
@@ -134,14 +144,28 @@ func (r *KdoChartReconciler) apply(spec *kdov1a2.ChartSpec, progressCb k8s.Progr
 	if err != nil {
 		return err
 	}
+	ctx, cancel := r.context()
+	defer cancel()
 	thread := &starlark.Thread{Name: "main", Load: r.Load}
-	chart, err := r.Repo.GetFromSpec(thread, spec)
+	kdo.SetContext(thread, ctx)
+	k8s = k8s.WithContext(ctx)
+	chart, err := r.Repo.GetFromSpec(thread, spec, r.chartOptions(k8s)...)
 	if err != nil {
 		return err
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Hour)
-	defer cancel()
-	return chart.Apply(thread, k8s.WithContext(ctx))
+	return chart.Apply(thread, k8s)
+}
+
+// chartOptions are the options to build a chart from the values it was applied with
+func (r *KdoChartReconciler) chartOptions(k k8s.K8s) []kdo.ChartOption {
+	options := []kdo.ChartOption{}
+	if r.ReuseValues {
+		options = append(options, kdo.WithReuseValues(k))
+	}
+	if r.KeyProvider != nil {
+		options = append(options, kdo.WithEncryption(r.KeyProvider))
+	}
+	return options
 }
 
 func (r *KdoChartReconciler) delete(spec *kdov1a2.ChartSpec, progressCb k8s.ProgressSubscription) error {
@@ -155,14 +179,108 @@ func (r *KdoChartReconciler) delete(spec *kdov1a2.ChartSpec, progressCb k8s.Prog
 	if err != nil {
 		return err
 	}
+	ctx, cancel := r.context()
+	defer cancel()
 	thread := &starlark.Thread{Name: "main", Load: r.Load}
-	chart, err := r.Repo.GetFromSpec(thread, spec)
+	kdo.SetContext(thread, ctx)
+	k8s = k8s.WithContext(ctx)
+	chart, err := r.Repo.GetFromSpec(thread, spec, r.chartOptions(k8s)...)
 	if err != nil {
 		return err
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Hour)
+	return chart.Delete(thread, k8s, &kdo.DeleteOptions{})
+}
+
+// UpdateStatus calls the status method of all successfully applied KdoCharts and stores the result in their status
+func (r *KdoChartReconciler) UpdateStatus() error {
+	var list kdov1a2.KdoChartList
+	if err := r.List(context.Background(), &list); err != nil {
+		return errors.Wrap(err, "error listing KdoCharts")
+	}
+	for i := range list.Items {
+		kdoChart := &list.Items[i]
+		if !kdoChart.ObjectMeta.DeletionTimestamp.IsZero() || kdoChart.Status.LastOp.Type != applyStatus || kdoChart.Status.LastOp.Progress != 100 {
+			continue
+		}
+		if err := r.updateStatus(kdoChart); err != nil {
+			r.Log.Error(err, "error updating status", "kdochart", kdoChart.Namespace+"/"+kdoChart.Name)
+		}
+	}
+	return nil
+}
+
+func (r *KdoChartReconciler) updateStatus(kdoChart *kdov1a2.KdoChart) error {
+	status, err := r.status(&kdoChart.Spec)
+	if err != nil {
+		return err
+	}
+	kdoChart.Status.Health = status.Health
+	if status.Ready() {
+		kdoChart.Status.Health = kdo.HealthReady
+	} else if status.Health == kdo.HealthReady {
+		kdoChart.Status.Health = kdo.HealthProgressing
+	}
+	kdoChart.Status.Charts = healthStatus(status, "")
+	now := metav1.Now()
+	kdoChart.Status.LastStatusUpdate = &now
+	return r.Status().Update(context.Background(), kdoChart)
+}
+
+// healthStatus flattens the status of a chart and its subcharts. Subcharts are named by their path.
+func healthStatus(status *kdo.ChartStatus, prefix string) []kdov1a2.HealthStatus {
+	name := prefix + status.Name
+	result := []kdov1a2.HealthStatus{{Name: name, Health: status.Health, Endpoints: status.Endpoints, Messages: status.Messages}}
+	for i := range status.Subcharts {
+		result = append(result, healthStatus(&status.Subcharts[i], name+"/")...)
+	}
+	return result
+}
+
+func (r *KdoChartReconciler) status(spec *kdov1a2.ChartSpec) (*kdo.ChartStatus, error) {
+	var tool k8s.Tool
+	if err := tool.Set(spec.Tool); err != nil {
+		return nil, err
+	}
+	k8s, err := r.K8s(k8s.WithKubeConfigContent(spec.KubeConfig), k8s.WithTool(tool))
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := r.context()
 	defer cancel()
-	return chart.Delete(thread, k8s.WithContext(ctx), &kdo.DeleteOptions{})
+	thread := &starlark.Thread{Name: "main", Load: r.Load}
+	kdo.SetContext(thread, ctx)
+	k8s = k8s.WithContext(ctx)
+	chart, err := r.Repo.GetFromSpec(thread, spec, r.chartOptions(k8s)...)
+	if err != nil {
+		return nil, err
+	}
+	return chart.Status(thread, k8s)
+}
+
+// StatusUpdater returns a runnable, which calls UpdateStatus periodically
+func (r *KdoChartReconciler) StatusUpdater(interval time.Duration) manager.Runnable {
+	return manager.RunnableFunc(func(stop <-chan struct{}) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return nil
+			case <-ticker.C:
+				if err := r.UpdateStatus(); err != nil {
+					r.Log.Error(err, "error updating status of KdoCharts")
+				}
+			}
+		}
+	})
+}
+
+func (r *KdoChartReconciler) context() (context.Context, context.CancelFunc) {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return context.WithTimeout(context.Background(), timeout)
 }
 
 // SetupWithManager -
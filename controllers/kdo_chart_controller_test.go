@@ -131,6 +131,33 @@ var _ = Describe("KdoChartReconciler", func() {
 			Expect(k.ApplyCallCount()).To(Equal(1))
 		})
 
+		It("updates the status of applied kdo charts", func() {
+			chart = &kdov1a2.KdoChart{
+				Spec: kdov1a2.ChartSpec{
+					ChartTgz: chartTgz,
+				},
+				Status: kdov1a2.ChartStatus{LastOp: kdov1a2.Operation{Type: applyStatus, Progress: 100}},
+			}
+			reconciler.Client.(*FakeClient).ListStub = func(ctx context.Context, list runtime.Object, options ...client.ListOption) error {
+				list.(*kdov1a2.KdoChartList).Items = []kdov1a2.KdoChart{*chart.DeepCopy()}
+				return nil
+			}
+			reconciler.ReuseValues = true
+			Expect(reconciler.UpdateStatus()).To(Succeed())
+			secrets := []string{}
+			for i := 0; i < k.GetCallCount(); i++ {
+				if kind, name, _ := k.GetArgsForCall(i); kind == "secret" {
+					secrets = append(secrets, name)
+				}
+			}
+			Expect(secrets).To(ContainElement("kdo.uaa"))
+			Expect(chart.Status.LastStatusUpdate).NotTo(BeNil())
+			Expect(chart.Status.Health).NotTo(BeEmpty())
+			Expect(chart.Status.Charts).NotTo(BeEmpty())
+			Expect(chart.Status.Charts[0].Name).To(Equal("uaa"))
+			Expect(k.ApplyCallCount()).To(Equal(0))
+		})
+
 		It("handles error correct during apply", func() {
 			chart = &kdov1a2.KdoChart{
 				Spec: kdov1a2.ChartSpec{
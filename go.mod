@@ -4,12 +4,15 @@ go 1.13
 
 require (
 	code.cloudfoundry.org/lager v2.0.0+incompatible
+	filippo.io/age v1.0.0
+	github.com/BurntSushi/toml v0.3.1
 	github.com/Masterminds/semver/v3 v3.0.3
 	github.com/Masterminds/sprig/v3 v3.0.2
 	github.com/drewolson/testflight v1.0.0 // indirect
 	github.com/evanphx/json-patch v4.9.0+incompatible
 	github.com/fatih/color v1.9.0
 	github.com/go-logr/logr v0.1.0
+	github.com/gobwas/glob v0.2.3
 	github.com/google/uuid v1.1.1
 	github.com/gorilla/mux v1.8.0 // indirect
 	github.com/k14s/starlark-go v0.0.0-20200720175618-3a5c849cc368
@@ -26,16 +29,19 @@ require (
 	github.com/sabhiram/go-gitignore v0.0.0-20180611051255-d3107576ba94
 	github.com/spf13/cobra v1.0.0
 	github.com/spf13/pflag v1.0.5
-	golang.org/x/crypto v0.0.0-20191011191535-87dc89f01550
-	golang.org/x/sys v0.0.0-20200625212154-ddb9806d33ae // indirect
+	github.com/xeipuuv/gojsonschema v1.1.0
+	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5
 	golang.org/x/tools v0.0.0-20200407041343-bf15fae40dea // indirect
 	gopkg.in/yaml.v2 v2.2.8
+	helm.sh/helm/v3 v3.1.3
 	k8s.io/api v0.17.2
 	k8s.io/apimachinery v0.17.4
+	k8s.io/cli-runtime v0.17.2
 	k8s.io/client-go v0.17.2
 	sigs.k8s.io/controller-runtime v0.5.2
 	sigs.k8s.io/go-open-service-broker-client/v2 v2.0.0-20200911103215-9787cad28392
-
+	sigs.k8s.io/kustomize/api v0.4.1
+	sigs.k8s.io/yaml v1.2.0
 )
 
 replace github.com/k14s/ytt => github.com/wonderix/ytt v0.28.1-0.20200908051131-36914082e903
@@ -1,6 +1,7 @@
 package extensions
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/k14s/starlark-go/starlarkstruct"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
 	"github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo"
 	"github.com/sap/kubernetes-deployment-orchestrator/pkg/starutils"
 	"github.com/spf13/pflag"
@@ -72,6 +74,7 @@ func (o *OsbConfig) factory() (osb.Client, error) {
 }
 
 type osbBindingBackend struct {
+	ctx               context.Context
 	clientFactory     osbClientFactory
 	service           string
 	plan              string
@@ -149,29 +152,15 @@ func (v *osbBindingBackend) Apply(m map[string][]byte) (map[string][]byte, error
 		return nil, err
 	}
 	if provisionResponse.Async {
-		for {
-			pollResponse, err := client.PollLastOperation(&osb.LastOperationRequest{
-				InstanceID:          instanceID,
-				ServiceID:           &selectedService.ID,
-				PlanID:              &selectedPlan.ID,
-				OperationKey:        provisionResponse.OperationKey,
-				OriginatingIdentity: nil,
-			})
-			if err != nil {
-				return nil, fmt.Errorf("Provisioning of service '%s' with plan '%s' failed: %s", v.plan, v.service, err.Error())
-			}
-			switch pollResponse.State {
-			case osb.StateSucceeded:
-				break
-			case osb.StateFailed:
-				return nil, fmt.Errorf("Provisioning of service '%s' with plan '%s' failed", v.plan, v.service)
-			case osb.StateInProgress:
-				delay := 10 * time.Second
-				if pollResponse.PollDelay != nil {
-					delay = *pollResponse.PollDelay
-				}
-				time.Sleep(delay)
-			}
+		err := v.poll(client, &osb.LastOperationRequest{
+			InstanceID:          instanceID,
+			ServiceID:           &selectedService.ID,
+			PlanID:              &selectedPlan.ID,
+			OperationKey:        provisionResponse.OperationKey,
+			OriginatingIdentity: nil,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Provisioning of service '%s' with plan '%s' failed: %s", v.plan, v.service, err.Error())
 		}
 	}
 	bindingID := uuid.New().String()
@@ -246,38 +235,57 @@ func (v *osbBindingBackend) Delete(m map[string][]byte) error {
 		OriginatingIdentity: nil,
 	})
 	if deprovisionResponse.Async {
-		for {
-			pollResponse, err := client.PollLastOperation(&osb.LastOperationRequest{
-				InstanceID:          instanceID,
-				ServiceID:           &serviceID,
-				PlanID:              &planID,
-				OperationKey:        deprovisionResponse.OperationKey,
-				OriginatingIdentity: nil,
-			})
-			if err != nil {
-				return fmt.Errorf("Deprovisioning of service '%s' with plan '%s' failed: %s", v.plan, v.service, err.Error())
-			}
-			switch pollResponse.State {
-			case osb.StateSucceeded:
-				break
-			case osb.StateFailed:
-				return fmt.Errorf("Deprovisioning of service '%s' with plan '%s' failed", v.plan, v.service)
-			case osb.StateInProgress:
-				delay := 10 * time.Second
-				if pollResponse.PollDelay != nil {
-					delay = *pollResponse.PollDelay
-				}
-				time.Sleep(delay)
-			}
+		err := v.poll(client, &osb.LastOperationRequest{
+			InstanceID:          instanceID,
+			ServiceID:           &serviceID,
+			PlanID:              &planID,
+			OperationKey:        deprovisionResponse.OperationKey,
+			OriginatingIdentity: nil,
+		})
+		if err != nil {
+			return fmt.Errorf("Deprovisioning of service '%s' with plan '%s' failed: %s", v.plan, v.service, err.Error())
 		}
 	}
 	return nil
 }
 
+// poll waits until the last operation succeeded, failed or the context is done
+func (v *osbBindingBackend) poll(client osb.Client, request *osb.LastOperationRequest) error {
+	ctx := v.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pollResponse, err := client.PollLastOperation(request)
+		if err != nil {
+			return err
+		}
+		switch pollResponse.State {
+		case osb.StateSucceeded:
+			return nil
+		case osb.StateFailed:
+			if pollResponse.Description != nil {
+				return errors.New(*pollResponse.Description)
+			}
+			return errors.New("last operation failed")
+		}
+		delay := 10 * time.Second
+		if pollResponse.PollDelay != nil {
+			delay = *pollResponse.PollDelay
+		}
+		if err := k8s.Sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
 func makeOsbBindung(clientFactory osbClientFactory) func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	return func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 
-		c := &osbBindingBackend{clientFactory: clientFactory}
+		c := &osbBindingBackend{clientFactory: clientFactory, ctx: kdo.GetContext(thread)}
 		var name string
 		var parameters starlark.IterableMapping
 		var bindingParameters starlark.IterableMapping
@@ -14,6 +14,7 @@ import (
 	"github.com/pivotal-cf/brokerapi/fakes"
 
 	osb "sigs.k8s.io/go-open-service-broker-client/v2"
+	osbfake "sigs.k8s.io/go-open-service-broker-client/v2/fake"
 )
 
 var _ = Describe("osb binding", func() {
@@ -112,3 +113,28 @@ var _ = Describe("osb binding", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 })
+
+var _ = Describe("osb polling", func() {
+	It("stops on success", func() {
+		polls := 0
+		client := &osbfake.FakeClient{PollLastOperationReaction: osbfake.DynamicPollLastOperationReaction(func(*osb.LastOperationRequest) (*osb.LastOperationResponse, error) {
+			polls++
+			if polls < 3 {
+				delay := time.Millisecond
+				return &osb.LastOperationResponse{State: osb.StateInProgress, PollDelay: &delay}, nil
+			}
+			return &osb.LastOperationResponse{State: osb.StateSucceeded}, nil
+		})}
+		binding := osbBindingBackend{}
+		Expect(binding.poll(client, &osb.LastOperationRequest{})).To(Succeed())
+		Expect(polls).To(Equal(3))
+	})
+
+	It("stops if the context is done", func() {
+		client := &osbfake.FakeClient{PollLastOperationReaction: &osbfake.PollLastOperationReaction{Response: &osb.LastOperationResponse{State: osb.StateInProgress}}}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		binding := osbBindingBackend{ctx: ctx}
+		Expect(binding.poll(client, &osb.LastOperationRequest{})).To(MatchError(context.DeadlineExceeded))
+	})
+})
@@ -0,0 +1,41 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CancelledError is returned if an operation is aborted, because its context is cancelled or has exceeded its deadline
+type CancelledError struct {
+	Chart     string
+	Operation string
+	Err       error
+}
+
+func (e *CancelledError) Error() string {
+	return fmt.Sprintf("cancelled in chart %s while doing %s: %s", e.Chart, e.Operation, e.Err.Error())
+}
+
+// Cancelled converts err into a CancelledError if ctx is done. Otherwise err is returned unchanged.
+func Cancelled(ctx context.Context, chart string, operation string, err error) error {
+	if ctx == nil || ctx.Err() == nil {
+		return err
+	}
+	if _, ok := err.(*CancelledError); ok {
+		return err
+	}
+	return &CancelledError{Chart: chart, Operation: operation, Err: ctx.Err()}
+}
+
+// Sleep waits for the given duration. It returns early with the error of the context if ctx is done.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
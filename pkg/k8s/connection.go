@@ -0,0 +1,96 @@
+package k8s
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/rest"
+)
+
+// ConnectionOptions tune the connection to the kubernetes api server
+type ConnectionOptions struct {
+	QPS                   float32
+	Burst                 int
+	RequestTimeout        time.Duration
+	TLSServerName         string
+	InsecureSkipTLSVerify bool
+	Proxy                 string
+}
+
+// AddFlags -
+func (o *ConnectionOptions) AddFlags(flagsSet *pflag.FlagSet) {
+	flagsSet.Float32Var(&o.QPS, "qps", rest.DefaultQPS, "Maximum number of requests per second to the api server")
+	flagsSet.IntVar(&o.Burst, "burst", rest.DefaultBurst, "Maximum burst of requests to the api server")
+	flagsSet.DurationVar(&o.RequestTimeout, "request-timeout", 0, "Timeout of a single request to the api server (0 means no timeout)")
+	flagsSet.StringVar(&o.TLSServerName, "tls-server-name", "", "Server name used to verify the certificate of the api server")
+	flagsSet.BoolVar(&o.InsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "Don't verify the certificate of the api server")
+	flagsSet.StringVar(&o.Proxy, "proxy-url", "", "Proxy used to connect to the api server")
+}
+
+// ConfigureRest applies the connection options to a rest configuration, e.g. the one of the controller manager
+func (v *Configs) ConfigureRest(config *rest.Config) error {
+	return v.connection.apply(config)
+}
+
+// apply modifies the rest configuration according to the options
+func (o *ConnectionOptions) apply(config *rest.Config) error {
+	if o.QPS > 0 {
+		config.QPS = o.QPS
+	}
+	if o.Burst > 0 {
+		config.Burst = o.Burst
+	}
+	if o.RequestTimeout > 0 {
+		config.Timeout = o.RequestTimeout
+	}
+	if o.TLSServerName != "" {
+		config.TLSClientConfig.ServerName = o.TLSServerName
+	}
+	if o.InsecureSkipTLSVerify {
+		config.TLSClientConfig.Insecure = true
+		config.TLSClientConfig.CAFile = ""
+		config.TLSClientConfig.CAData = nil
+	}
+	if o.Proxy != "" {
+		proxy, err := url.Parse(o.Proxy)
+		if err != nil {
+			return fmt.Errorf("invalid proxy url %s: %s", o.Proxy, err.Error())
+		}
+		config.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+			transport, ok := rt.(*http.Transport)
+			if !ok {
+				return rt
+			}
+			transport = transport.Clone()
+			transport.Proxy = http.ProxyURL(proxy)
+			return transport
+		}
+	}
+	return nil
+}
+
+// kubectlFlags returns the options supported by kubectl
+func (o *ConnectionOptions) kubectlFlags() []string {
+	flags := []string{}
+	if o.RequestTimeout > 0 {
+		flags = append(flags, "--request-timeout", o.RequestTimeout.String())
+	}
+	if o.TLSServerName != "" {
+		flags = append(flags, "--tls-server-name", o.TLSServerName)
+	}
+	if o.InsecureSkipTLSVerify {
+		flags = append(flags, "--insecure-skip-tls-verify")
+	}
+	return flags
+}
+
+// env returns the environment for kubectl and kapp
+func (o *ConnectionOptions) env() []string {
+	if o.Proxy == "" {
+		return nil
+	}
+	return []string{"HTTPS_PROXY=" + o.Proxy, "HTTP_PROXY=" + o.Proxy}
+}
@@ -0,0 +1,69 @@
+package k8s
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("connection", func() {
+
+	It("adds flags", func() {
+		configs := Configs{}
+		flagsSet := pflag.FlagSet{}
+		configs.AddFlags(&flagsSet)
+		Expect(flagsSet.Parse([]string{"--qps", "50", "--burst", "100", "--request-timeout", "30s", "--proxy-url", "http://proxy:3128"})).To(Succeed())
+		Expect(configs.connection).To(Equal(ConnectionOptions{QPS: 50, Burst: 100, RequestTimeout: 30 * time.Second, Proxy: "http://proxy:3128"}))
+	})
+
+	It("configures the rest client", func() {
+		options := ConnectionOptions{QPS: 50, Burst: 100, RequestTimeout: time.Minute, TLSServerName: "api.local", InsecureSkipTLSVerify: true, Proxy: "http://proxy:3128"}
+		config := &rest.Config{TLSClientConfig: rest.TLSClientConfig{CAData: []byte("ca")}}
+		Expect(options.apply(config)).To(Succeed())
+		Expect(config.QPS).To(BeEquivalentTo(50))
+		Expect(config.Burst).To(Equal(100))
+		Expect(config.Timeout).To(Equal(time.Minute))
+		Expect(config.TLSClientConfig.ServerName).To(Equal("api.local"))
+		Expect(config.TLSClientConfig.Insecure).To(BeTrue())
+		Expect(config.TLSClientConfig.CAData).To(BeNil())
+		transport := config.WrapTransport(&http.Transport{}).(*http.Transport)
+		proxy, err := transport.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "api.local"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proxy.String()).To(Equal("http://proxy:3128"))
+
+		Expect((&ConnectionOptions{Proxy: ":invalid"}).apply(&rest.Config{})).To(HaveOccurred())
+	})
+
+	It("passes options to kubectl", func() {
+		options := ConnectionOptions{RequestTimeout: time.Minute, TLSServerName: "api.local", InsecureSkipTLSVerify: true, Proxy: "http://proxy:3128"}
+		Expect(options.kubectlFlags()).To(Equal([]string{"--request-timeout", "1m0s", "--tls-server-name", "api.local", "--insecure-skip-tls-verify"}))
+		Expect(options.env()).To(ContainElement("HTTPS_PROXY=http://proxy:3128"))
+		Expect((&ConnectionOptions{}).env()).To(BeNil())
+	})
+
+	It("resolves custom resources using discovery", func() {
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(schema.GroupVersionKind{Group: "kdo.sap.github.com", Version: "v1alpha2", Kind: "KdoChart"}, meta.RESTScopeNamespace)
+		r := request{mapper: mapper, resource: "kdochart"}
+		gv, err := r.groupVersionResource()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gv).To(Equal(schema.GroupVersionKind{Group: "kdo.sap.github.com", Version: "v1alpha2", Kind: "kdocharts"}))
+		r.resource = "kdocharts.kdo.sap.github.com"
+		_, err = r.groupVersionResource()
+		Expect(err).NotTo(HaveOccurred())
+		r.resource = "unknown"
+		_, err = r.groupVersionResource()
+		Expect(err).To(BeAssignableToTypeOf(&errUnknownResource{}))
+		r.resource = "deployment"
+		gv, err = r.groupVersionResource()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gv.Group).To(Equal("apps"))
+	})
+})
@@ -32,6 +32,16 @@ type FakeK8s struct {
 	configContentReturnsOnCall map[int]struct {
 		result1 *string
 	}
+	ContextStub        func() context.Context
+	contextMutex       sync.RWMutex
+	contextArgsForCall []struct {
+	}
+	contextReturns struct {
+		result1 context.Context
+	}
+	contextReturnsOnCall map[int]struct {
+		result1 context.Context
+	}
 	CreateOrUpdateStub        func(*Object, func(obj *Object) error, *Options) (*Object, error)
 	createOrUpdateMutex       sync.RWMutex
 	createOrUpdateArgsForCall []struct {
@@ -158,6 +168,19 @@ type FakeK8s struct {
 	isNotExistReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	KappAppChangesStub        func(*Options) ([]map[string]string, error)
+	kappAppChangesMutex       sync.RWMutex
+	kappAppChangesArgsForCall []struct {
+		arg1 *Options
+	}
+	kappAppChangesReturns struct {
+		result1 []map[string]string
+		result2 error
+	}
+	kappAppChangesReturnsOnCall map[int]struct {
+		result1 []map[string]string
+		result2 error
+	}
 	ListStub        func(string, *Options, *ListOptions) (*Object, error)
 	listMutex       sync.RWMutex
 	listArgsForCall []struct {
@@ -389,6 +412,58 @@ func (fake *FakeK8s) ConfigContentReturnsOnCall(i int, result1 *string) {
 	}{result1}
 }
 
+func (fake *FakeK8s) Context() context.Context {
+	fake.contextMutex.Lock()
+	ret, specificReturn := fake.contextReturnsOnCall[len(fake.contextArgsForCall)]
+	fake.contextArgsForCall = append(fake.contextArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Context", []interface{}{})
+	fake.contextMutex.Unlock()
+	if fake.ContextStub != nil {
+		return fake.ContextStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.contextReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeK8s) ContextCallCount() int {
+	fake.contextMutex.RLock()
+	defer fake.contextMutex.RUnlock()
+	return len(fake.contextArgsForCall)
+}
+
+func (fake *FakeK8s) ContextCalls(stub func() context.Context) {
+	fake.contextMutex.Lock()
+	defer fake.contextMutex.Unlock()
+	fake.ContextStub = stub
+}
+
+func (fake *FakeK8s) ContextReturns(result1 context.Context) {
+	fake.contextMutex.Lock()
+	defer fake.contextMutex.Unlock()
+	fake.ContextStub = nil
+	fake.contextReturns = struct {
+		result1 context.Context
+	}{result1}
+}
+
+func (fake *FakeK8s) ContextReturnsOnCall(i int, result1 context.Context) {
+	fake.contextMutex.Lock()
+	defer fake.contextMutex.Unlock()
+	fake.ContextStub = nil
+	if fake.contextReturnsOnCall == nil {
+		fake.contextReturnsOnCall = make(map[int]struct {
+			result1 context.Context
+		})
+	}
+	fake.contextReturnsOnCall[i] = struct {
+		result1 context.Context
+	}{result1}
+}
+
 func (fake *FakeK8s) CreateOrUpdate(arg1 *Object, arg2 func(obj *Object) error, arg3 *Options) (*Object, error) {
 	fake.createOrUpdateMutex.Lock()
 	ret, specificReturn := fake.createOrUpdateReturnsOnCall[len(fake.createOrUpdateArgsForCall)]
@@ -994,6 +1069,69 @@ func (fake *FakeK8s) IsNotExistReturnsOnCall(i int, result1 bool) {
 	}{result1}
 }
 
+func (fake *FakeK8s) KappAppChanges(arg1 *Options) ([]map[string]string, error) {
+	fake.kappAppChangesMutex.Lock()
+	ret, specificReturn := fake.kappAppChangesReturnsOnCall[len(fake.kappAppChangesArgsForCall)]
+	fake.kappAppChangesArgsForCall = append(fake.kappAppChangesArgsForCall, struct {
+		arg1 *Options
+	}{arg1})
+	fake.recordInvocation("KappAppChanges", []interface{}{arg1})
+	fake.kappAppChangesMutex.Unlock()
+	if fake.KappAppChangesStub != nil {
+		return fake.KappAppChangesStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.kappAppChangesReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeK8s) KappAppChangesCallCount() int {
+	fake.kappAppChangesMutex.RLock()
+	defer fake.kappAppChangesMutex.RUnlock()
+	return len(fake.kappAppChangesArgsForCall)
+}
+
+func (fake *FakeK8s) KappAppChangesCalls(stub func(*Options) ([]map[string]string, error)) {
+	fake.kappAppChangesMutex.Lock()
+	defer fake.kappAppChangesMutex.Unlock()
+	fake.KappAppChangesStub = stub
+}
+
+func (fake *FakeK8s) KappAppChangesArgsForCall(i int) *Options {
+	fake.kappAppChangesMutex.RLock()
+	defer fake.kappAppChangesMutex.RUnlock()
+	argsForCall := fake.kappAppChangesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeK8s) KappAppChangesReturns(result1 []map[string]string, result2 error) {
+	fake.kappAppChangesMutex.Lock()
+	defer fake.kappAppChangesMutex.Unlock()
+	fake.KappAppChangesStub = nil
+	fake.kappAppChangesReturns = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeK8s) KappAppChangesReturnsOnCall(i int, result1 []map[string]string, result2 error) {
+	fake.kappAppChangesMutex.Lock()
+	defer fake.kappAppChangesMutex.Unlock()
+	fake.KappAppChangesStub = nil
+	if fake.kappAppChangesReturnsOnCall == nil {
+		fake.kappAppChangesReturnsOnCall = make(map[int]struct {
+			result1 []map[string]string
+			result2 error
+		})
+	}
+	fake.kappAppChangesReturnsOnCall[i] = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeK8s) List(arg1 string, arg2 *Options, arg3 *ListOptions) (*Object, error) {
 	fake.listMutex.Lock()
 	ret, specificReturn := fake.listReturnsOnCall[len(fake.listArgsForCall)]
@@ -1554,6 +1692,8 @@ func (fake *FakeK8s) Invocations() map[string][][]interface{} {
 	defer fake.applyMutex.RUnlock()
 	fake.configContentMutex.RLock()
 	defer fake.configContentMutex.RUnlock()
+	fake.contextMutex.RLock()
+	defer fake.contextMutex.RUnlock()
 	fake.createOrUpdateMutex.RLock()
 	defer fake.createOrUpdateMutex.RUnlock()
 	fake.deleteMutex.RLock()
@@ -1574,6 +1714,8 @@ func (fake *FakeK8s) Invocations() map[string][][]interface{} {
 	defer fake.inspectMutex.RUnlock()
 	fake.isNotExistMutex.RLock()
 	defer fake.isNotExistMutex.RUnlock()
+	fake.kappAppChangesMutex.RLock()
+	defer fake.kappAppChangesMutex.RUnlock()
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
 	fake.namespaceMutex.RLock()
@@ -13,8 +13,8 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -69,10 +69,12 @@ type K8s interface {
 	ConfigContent() *string
 	ForConfig(config string) (K8s, error)
 	WithContext(ctx context.Context) K8s
+	Context() context.Context
 	Progress(progress int)
 	Tool() Tool
 	SetTool(tool Tool)
 	Namespace(options *Options) *string
+	KappAppChanges(options *Options) ([]map[string]string, error)
 }
 
 // ProgressSubscription -
@@ -141,6 +143,8 @@ type Configs struct {
 	kubeConfig           string
 	progress             int
 	verbose              int
+	kappOptions          KappOptions
+	connection           ConnectionOptions
 }
 
 // Config -
@@ -156,6 +160,16 @@ func WithProgressSubscription(value ProgressSubscription) Config {
 	return func(options *Configs) error { options.progressSubscription = value; return nil }
 }
 
+// WithKappOptions -
+func WithKappOptions(value KappOptions) Config {
+	return func(options *Configs) error { options.kappOptions = value; return nil }
+}
+
+// WithConnectionOptions -
+func WithConnectionOptions(value ConnectionOptions) Config {
+	return func(options *Configs) error { options.connection = value; return nil }
+}
+
 // WithVerbose -
 func WithVerbose(value int) Config {
 	return func(options *Configs) error { options.verbose = value; return nil }
@@ -204,12 +218,14 @@ func (v *Configs) SetTool(tool Tool) {
 func (v *Configs) AddFlags(flagsSet *pflag.FlagSet) {
 	flagsSet.VarP(&v.tool, "tool", "t", "Tool to do the installation. Possible values kubectl (default) and kapp")
 	flagsSet.IntVarP(&v.verbose, "verbose", "v", 0, "Set kubectl verbose level")
+	v.kappOptions.AddFlags(flagsSet)
+	v.connection.AddFlags(flagsSet)
 }
 
 // NewK8s create new instance to interact with kubernetes
 func NewK8s(configs ...Config) (K8s, error) {
 	var err error
-	result := &k8sImpl{ctx: context.Background(), app: "root"}
+	result := &k8sImpl{ctx: context.Background(), app: "root", progressMutex: &sync.Mutex{}}
 	for _, config := range configs {
 		if err = config(&result.Configs); err != nil {
 			return nil, err
@@ -223,6 +239,9 @@ func (k *k8sImpl) connect() (K8s, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err = k.connection.apply(config); err != nil {
+		return nil, err
+	}
 	k.client, err = newK8sClient(config)
 	if err != nil {
 		return nil, err
@@ -251,6 +270,7 @@ type k8sImpl struct {
 	client           *k8sClient
 	host             string
 	ctx              context.Context
+	progressMutex    *sync.Mutex
 }
 
 var (
@@ -272,10 +292,26 @@ func (k *k8sImpl) progressCb(matched int, count int) {
 	if count == 0 || matched > count {
 		return
 	}
+	defer k.lockProgress()()
 	k.localProgress = matched * 90 / count
 	k.reportProgress()
 }
 
+// lockProgress serializes progress reporting of all charts sharing the same root, which may run in parallel
+func (k *k8sImpl) lockProgress() func() {
+	if k.progressMutex == nil {
+		return func() {}
+	}
+	k.progressMutex.Lock()
+	return k.progressMutex.Unlock
+}
+
+// Progress -
+func (k *k8sImpl) Progress(progress int) {
+	defer k.lockProgress()()
+	k.Configs.Progress(progress)
+}
+
 // Apply -
 func (k *k8sImpl) Apply(output ObjectStream, options *Options) (err error) {
 	if k.tool == ToolKapp {
@@ -285,7 +321,7 @@ func (k *k8sImpl) Apply(output ObjectStream, options *Options) (err error) {
 		writer, stream := prepareKubectl(output, false, k.objMapper(), k.progressCb)
 		err = runWithStdin(k.kubectl("apply", options, "-f", "-"), stream, writer, k.verbose)
 	}
-	return err
+	return k.cancelled("apply", err)
 }
 
 func (k *k8sImpl) reportProgress() {
@@ -293,13 +329,14 @@ func (k *k8sImpl) reportProgress() {
 	for _, p := range k.childrenProgress {
 		sum += p
 	}
-	k.Progress(sum / (k.children + 1))
+	k.Configs.Progress(sum / (k.children + 1))
 }
 
 func (k *k8sImpl) addProgressSubscription() ProgressSubscription {
 	if k.progressSubscription == nil {
 		return nil
 	}
+	defer k.lockProgress()()
 	index := len(k.childrenProgress)
 	k.childrenProgress = append(k.childrenProgress, 0)
 	return func(progress int) {
@@ -309,12 +346,14 @@ func (k *k8sImpl) addProgressSubscription() ProgressSubscription {
 }
 
 func (k *k8sImpl) clone() *k8sImpl {
-	return &k8sImpl{namespace: k.namespace, app: k.app, version: k.version, client: k.client, host: k.host, ctx: k.ctx,
+	return &k8sImpl{namespace: k.namespace, app: k.app, version: k.version, client: k.client, host: k.host, ctx: k.ctx, progressMutex: k.progressMutex,
 		Configs: Configs{
 			progressSubscription: k.addProgressSubscription(),
 			kubeConfig:           k.kubeConfig,
 			tool:                 ToolKubectl,
 			verbose:              k.verbose,
+			kappOptions:          k.kappOptions,
+			connection:           k.connection,
 		}}
 }
 
@@ -332,6 +371,16 @@ func (k *k8sImpl) WithContext(ctx context.Context) K8s {
 	return k
 }
 
+// Context -
+func (k *k8sImpl) Context() context.Context {
+	return k.ctx
+}
+
+// cancelled reports err as cancellation of the given operation if the context of k is done
+func (k *k8sImpl) cancelled(operation string, err error) error {
+	return Cancelled(k.ctx, k.app, operation, err)
+}
+
 // Delete -
 func (k *k8sImpl) Delete(output ObjectStream, options *Options) (err error) {
 	if k.tool == ToolKapp {
@@ -344,7 +393,7 @@ func (k *k8sImpl) Delete(output ObjectStream, options *Options) (err error) {
 	if err != nil && k.IsNotExist(err) {
 		err = nil
 	}
-	return err
+	return k.cancelled("delete", err)
 }
 
 var invalidValueRegex = regexp.MustCompile("[^a-zA-Z0-9\\-_\\.]")
@@ -368,11 +417,12 @@ func (k *k8sImpl) objMapper() func(obj *Object) *Object {
 
 // Delete -
 func (k *k8sImpl) DeleteObject(kind string, name string, options *Options) error {
-	return run(k.kubectl("delete", options, kind, name, "--ignore-not-found"))
+	return k.cancelled("delete "+kind+"/"+name, run(k.kubectl("delete", options, kind, name, "--ignore-not-found")))
 }
 
 // RolloutStatus -
 func (k *k8sImpl) RolloutStatus(kind string, name string, options *Options) error {
+	operation := "rollout status " + kind + "/" + name
 	start := time.Now()
 	for {
 		err := run(k.kubectl("rollout", options, "status", kind, name))
@@ -380,19 +430,21 @@ func (k *k8sImpl) RolloutStatus(kind string, name string, options *Options) erro
 			return nil
 		}
 		if !k.IsNotExist(err) {
-			return err
+			return k.cancelled(operation, err)
 		}
 		if options.Timeout > 0 {
 			if time.Since(start) > options.Timeout {
 				return fmt.Errorf("Timeout during waiting for %s %s", kind, name)
 			}
 		}
-		time.Sleep(10 * time.Second)
+		if err := Sleep(k.ctx, 10*time.Second); err != nil {
+			return k.cancelled(operation, err)
+		}
 	}
 }
 
 func (k *k8sImpl) Wait(kind string, name string, condition string, options *Options) error {
-	return run(k.kubectl("wait", options, kind, name, "--for", condition))
+	return k.cancelled("wait for "+kind+"/"+name, run(k.kubectl("wait", options, kind, name, "--for", condition)))
 }
 
 func wrapError(err error) error {
@@ -416,13 +468,14 @@ func ignoreNotFound(obj *Object, err error, options *Options) (*Object, error) {
 // Get -
 func (k *k8sImpl) Get(kind string, name string, options *Options) (*Object, error) {
 	if k.client != nil {
-		obj, err := k.client.Get().Namespace(k.Namespace(options)).Resource(kind).Name(name).Do().Get()
+		obj, err := k.client.Get().Context(k.ctx).Namespace(k.Namespace(options)).Resource(kind).Name(name).Do().Get()
 		if err == nil {
 			return obj, nil
 		}
 		_, ok := err.(*errUnknownResource)
 		if !ok {
-			return ignoreNotFound(obj, err, options)
+			obj, err = ignoreNotFound(obj, err, options)
+			return obj, k.cancelled("get "+kind+"/"+name, err)
 		}
 	}
 
@@ -430,7 +483,7 @@ func (k *k8sImpl) Get(kind string, name string, options *Options) (*Object, erro
 	buffer := &bytes.Buffer{}
 	cmd.Stdout = buffer
 	if err := run(cmd); err != nil {
-		return nil, err
+		return nil, k.cancelled("get "+kind+"/"+name, err)
 	}
 	decoder := json.NewDecoder(buffer)
 	var result Object
@@ -443,7 +496,7 @@ func (k *k8sImpl) Patch(kind string, name string, pt types.PatchType, patch stri
 	if k.client == nil {
 		return nil, errors.New("Not connected")
 	}
-	obj, err := k.client.Patch(pt).Namespace(k.Namespace(options)).Resource(kind).Name(name).Body([]byte(patch)).Do().Get()
+	obj, err := k.client.Patch(pt).Context(k.ctx).Namespace(k.Namespace(options)).Resource(kind).Name(name).Body([]byte(patch)).Do().Get()
 	if err != nil {
 		if options.IgnoreNotFound {
 			statusError, ok := err.(*k8serrors.StatusError)
@@ -453,8 +506,8 @@ func (k *k8sImpl) Patch(kind string, name string, pt types.PatchType, patch stri
 				}
 			}
 		}
-		return ignoreNotFound(obj, err, options)
-
+		obj, err = ignoreNotFound(obj, err, options)
+		return obj, k.cancelled("patch "+kind+"/"+name, err)
 	}
 	return obj, nil
 }
@@ -469,10 +522,10 @@ func (k *k8sImpl) CreateOrUpdate(obj *Object, mutate func(obj *Object) error, op
 		if !k.IsNotExist(err) {
 			return nil, err
 		}
-		req = k.client.Post().Namespace(k.Namespace(options)).Resource(obj.Kind)
+		req = k.client.Post().Context(k.ctx).Namespace(k.Namespace(options)).Resource(obj.Kind)
 	} else {
 		obj = old
-		req = k.client.Put().Namespace(k.Namespace(options)).Resource(obj.Kind).Name(obj.MetaData.Name)
+		req = k.client.Put().Context(k.ctx).Namespace(k.Namespace(options)).Resource(obj.Kind).Name(obj.MetaData.Name)
 	}
 	err = mutate(obj)
 	if err != nil {
@@ -482,20 +535,20 @@ func (k *k8sImpl) CreateOrUpdate(obj *Object, mutate func(obj *Object) error, op
 	if err != nil {
 		return nil, err
 	}
-	return req.Body(body).Do().Get()
-
+	result, err := req.Body(body).Do().Get()
+	return result, k.cancelled("update "+obj.Kind+"/"+obj.MetaData.Name, err)
 }
 
 func (k *k8sImpl) DeleteByName(kind string, name string, options *Options) error {
 	if k.client == nil {
 		return errors.New("Not connected")
 	}
-	err := k.client.Delete().Namespace(k.Namespace(options)).Resource(kind).Name(name).Do().Error()
+	err := k.client.Delete().Context(k.ctx).Namespace(k.Namespace(options)).Resource(kind).Name(name).Do().Error()
 	if err != nil {
 		if options.IgnoreNotFound && k8serrors.IsNotFound(err) {
 			return nil
 		}
-		return err
+		return k.cancelled("delete "+kind+"/"+name, err)
 	}
 	return nil
 }
@@ -517,7 +570,7 @@ func (k *k8sImpl) List(kind string, options *Options, listOptions *ListOptions)
 	buffer := &bytes.Buffer{}
 	cmd.Stdout = buffer
 	if err := run(cmd); err != nil {
-		return nil, err
+		return nil, k.cancelled("list "+kind, err)
 	}
 	decoder := json.NewDecoder(buffer)
 	var result Object
@@ -536,6 +589,13 @@ func (k *k8sImpl) Watch(kind string, name string, options *Options) ObjectStream
 		if err != nil {
 			return err
 		}
+		done := make(chan error, 1)
+		go func() {
+			// kubectl is killed if the context is done, closing the pipe stops decoding
+			err := cmd.Wait()
+			w.Close()
+			done <- err
+		}()
 		decoder := json.NewDecoder(reader)
 		for {
 			var obj Object
@@ -544,13 +604,15 @@ func (k *k8sImpl) Watch(kind string, name string, options *Options) ObjectStream
 			}
 			err := writer(&obj)
 			if err != nil {
+				_ = cmd.Process.Kill()
+				<-done
 				if _, ok := err.(*CancelObjectStream); ok {
 					return nil
 				}
 				return err
 			}
 		}
-		return cmd.Wait()
+		return k.cancelled("watch "+kind+"/"+name, <-done)
 	}
 }
 
@@ -624,11 +686,13 @@ func (k *k8sImpl) kubectl(command string, options *Options, flags ...string) *ex
 	if k.verbose != 0 {
 		flags = append(flags, fmt.Sprintf("-v=%d", k.verbose))
 	}
+	flags = append(flags, k.connection.kubectlFlags()...)
 	c := k.command
 	if c == nil {
 		c = exec.CommandContext
 	}
 	cmd := c(k.ctx, "kubectl", flags...)
+	k.setEnv(cmd)
 	if options.Quiet {
 		cmd.Stdout = &bytes.Buffer{}
 	} else {
@@ -651,21 +715,30 @@ func (k *k8sImpl) kapp(command string, options *Options, flags ...string) *exec.
 	} else if len(k.kubeConfig) != 0 {
 		flags = append(flags, "-n", "default")
 	}
-	if options.Timeout > 0 {
-		flags = append(flags, "--wait-timeout", fmt.Sprintf("%.0fs", options.Timeout.Seconds()))
-	}
+	flags = append(flags, k.kappOptions.flags(command, options)...)
 	c := k.command
 	if c == nil {
 		c = exec.CommandContext
 	}
-	flags = append(flags, "-a", k.app, "-y")
+	flags = append(flags, "-a", k.app, "-y", "--tty=false")
 	cmd := c(k.ctx, "kapp", flags...)
-	fmt.Println(cmd.String())
-	cmd.Stdout = os.Stdout
+	k.setEnv(cmd)
+	if options.Quiet {
+		cmd.Stdout = &bytes.Buffer{}
+	} else {
+		fmt.Println(cmd.String())
+		cmd.Stdout = os.Stdout
+	}
 	cmd.Stderr = os.Stderr
 	return cmd
 }
 
+func (k *k8sImpl) setEnv(cmd *exec.Cmd) {
+	if env := k.connection.env(); env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+}
+
 func runWithStdin(cmd *exec.Cmd, output func(io.Writer) error, progress io.Writer, verbose int) error {
 
 	writer, err := cmd.StdinPipe()
@@ -728,26 +801,6 @@ func prepareKubectl(in ObjectStream, reverse bool, mapper func(obj *Object) *Obj
 	return writer, prepare(in, reverse, mapper2)
 }
 
-var kappRegexp = regexp.MustCompile(`waiting.*\[(\d+)/(\d+)\s+done\]`)
-
-func prepareKapp(in ObjectStream, reverse bool, mapper func(obj *Object) *Object, progress func(matched int, count int)) (io.Writer, Stream) {
-	writer := &lineWriter{line: func(line string) {
-		match := kappRegexp.FindStringSubmatch(line)
-		if len(match) == 3 {
-			count, err := strconv.Atoi(match[2])
-			if err != nil || count == 0 {
-				return
-			}
-			matched, err := strconv.Atoi(match[1])
-			if err != nil {
-				return
-			}
-			progress(matched, count)
-		}
-	}}
-	return writer, prepare(in, reverse, mapper)
-}
-
 func compare(o1 *Object, o2 *Object) int {
 	diff := o1.kindOrdinal() - o2.kindOrdinal()
 	if diff != 0 {
@@ -1,6 +1,7 @@
 package k8s
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,18 +12,25 @@ import (
 
 	"k8s.io/apimachinery/pkg/types"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// k8sClient is shared by all clones created with ForSubChart. Therefore they share the rate limiter and the discovery cache.
 type k8sClient struct {
 	client *rest.RESTClient
+	mapper meta.RESTMapper
 }
 
 type request struct {
 	request   *rest.Request
+	mapper    meta.RESTMapper
 	namespace *string
 	resource  string
 	name      string
@@ -81,29 +89,56 @@ func newK8sClient(config *rest.Config) (*k8sClient, error) {
 	if err != nil {
 		return nil, err
 	}
-	scheme.Scheme.AllKnownTypes()
-	return &k8sClient{client: client}, nil
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(rest.CopyConfig(config))
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+	return &k8sClient{client: client, mapper: mapper}, nil
 
 }
 
 func (k *k8sClient) Get() request {
-	return request{request: k.client.Get()}
+	return request{request: k.client.Get(), mapper: k.mapper}
 }
 
 func (k *k8sClient) Patch(pt types.PatchType) request {
-	return request{request: k.client.Patch(pt)}
+	return request{request: k.client.Patch(pt), mapper: k.mapper}
 }
 
 func (k *k8sClient) Post() request {
-	return request{request: k.client.Post()}
+	return request{request: k.client.Post(), mapper: k.mapper}
 }
 
 func (k *k8sClient) Put() request {
-	return request{request: k.client.Put()}
+	return request{request: k.client.Put(), mapper: k.mapper}
 }
 
 func (k *k8sClient) Delete() request {
-	return request{request: k.client.Delete()}
+	return request{request: k.client.Delete(), mapper: k.mapper}
+}
+
+// groupVersionResource resolves the resource of the request. Resources which aren't known by the scheme, e.g. custom resources,
+// are looked up using the discovery information of the api server.
+func (r request) groupVersionResource() (schema.GroupVersionKind, error) {
+	gv, ok := kindToGroupVersionKind[strings.ToLower(r.resource)]
+	if ok {
+		return gv, nil
+	}
+	if r.mapper == nil {
+		return gv, &errUnknownResource{resource: r.resource}
+	}
+	resource := schema.ParseGroupResource(strings.ToLower(r.resource))
+	gvr, err := r.mapper.ResourceFor(resource.WithVersion(""))
+	if err != nil {
+		return gv, &errUnknownResource{resource: r.resource}
+	}
+	return schema.GroupVersionKind{Group: gvr.Group, Version: gvr.Version, Kind: gvr.Resource}, nil
+}
+
+func (r request) Context(ctx context.Context) request {
+	r.request.Context(ctx)
+	return r
 }
 
 func (r request) Namespace(namespace *string) request {
@@ -127,9 +162,9 @@ func (r request) Body(obj interface{}) request {
 }
 
 func (r request) Do() result {
-	gv, ok := kindToGroupVersionKind[strings.ToLower(r.resource)]
-	if !ok {
-		return result{err: &errUnknownResource{resource: r.resource}}
+	gv, err := r.groupVersionResource()
+	if err != nil {
+		return result{err: err}
 	}
 
 	prefix := ""
@@ -4,18 +4,67 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/Masterminds/semver/v3"
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 )
 
 // K8sInMemory in memory implementation of K8s
 type K8sInMemory struct {
 	namespace string
-	objects   map[string]Object
+	objects   *objectStore
+	ctx       context.Context
+}
+
+// objectStore is shared between all K8sInMemory instances created from one root and can be used concurrently
+type objectStore struct {
+	sync.RWMutex
+	objects map[string]Object
+}
+
+func (s *objectStore) get(key string) (Object, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	obj, ok := s.objects[key]
+	return obj, ok
+}
+
+func (s *objectStore) set(key string, obj Object) {
+	s.Lock()
+	defer s.Unlock()
+	s.objects[key] = obj
+}
+
+func (s *objectStore) delete(key string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.objects, key)
+}
+
+func (s *objectStore) keys() []string {
+	s.RLock()
+	defer s.RUnlock()
+	keys := []string{}
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (s *objectStore) all() map[string]Object {
+	s.RLock()
+	defer s.RUnlock()
+	objects := make(map[string]Object, len(s.objects))
+	for k, obj := range s.objects {
+		objects[k] = obj
+	}
+	return objects
 }
 
 type notFoundError string
@@ -26,9 +75,9 @@ func (e notFoundError) Error() string {
 
 // NewK8sInMemory creates a new K8sInMemory instance
 func NewK8sInMemory(namespace string, objects ...Object) *K8sInMemory {
-	result := &K8sInMemory{namespace: namespace, objects: map[string]Object{}}
+	result := &K8sInMemory{namespace: namespace, objects: &objectStore{objects: map[string]Object{}}}
 	for _, obj := range objects {
-		result.objects[result.key(obj.Kind, obj.MetaData.Name, obj.MetaData.Namespace, nil)] = obj
+		result.objects.set(result.key(obj.Kind, obj.MetaData.Name, obj.MetaData.Namespace, nil), obj)
 	}
 	return result
 }
@@ -47,12 +96,20 @@ func (k K8sInMemory) Host() string {
 
 // ForSubChart -
 func (k K8sInMemory) ForSubChart(namespace string, app string, version *semver.Version, children int) K8s {
-	return &K8sInMemory{namespace: namespace, objects: k.objects}
+	return &K8sInMemory{namespace: namespace, objects: k.objects, ctx: k.ctx}
 }
 
 // WithContext -
 func (k K8sInMemory) WithContext(ctx context.Context) K8s {
-	return &K8sInMemory{namespace: k.namespace, objects: k.objects}
+	return &K8sInMemory{namespace: k.namespace, objects: k.objects, ctx: ctx}
+}
+
+// Context -
+func (k K8sInMemory) Context() context.Context {
+	if k.ctx == nil {
+		return context.Background()
+	}
+	return k.ctx
 }
 
 // Inspect -
@@ -96,14 +153,14 @@ func (k K8sInMemory) Wait(kind string, name string, condition string, options *O
 
 // DeleteObject -
 func (k K8sInMemory) DeleteObject(kind string, name string, options *Options) error {
-	delete(k.objects, k.key(kind, name, "", options))
+	k.objects.delete(k.key(kind, name, "", options))
 	return nil
 }
 
 // Apply -
 func (k K8sInMemory) Apply(output ObjectStream, options *Options) error {
 	return output(func(obj *Object) error {
-		k.objects[k.key(obj.Kind, obj.MetaData.Name, obj.MetaData.Namespace, options)] = *obj
+		k.objects.set(k.key(obj.Kind, obj.MetaData.Name, obj.MetaData.Namespace, options), *obj)
 		return nil
 	})
 }
@@ -111,7 +168,7 @@ func (k K8sInMemory) Apply(output ObjectStream, options *Options) error {
 // Delete -
 func (k K8sInMemory) Delete(output ObjectStream, options *Options) error {
 	return output(func(obj *Object) error {
-		delete(k.objects, k.key(obj.Kind, obj.MetaData.Name, obj.MetaData.Namespace, options))
+		k.objects.delete(k.key(obj.Kind, obj.MetaData.Name, obj.MetaData.Namespace, options))
 		return nil
 	})
 }
@@ -147,13 +204,41 @@ func (k K8sInMemory) Patch(kind string, name string, pt types.PatchType, patchJS
 	if err != nil {
 		return nil, err
 	}
-	k.objects[k.key(obj.Kind, obj.MetaData.Name, obj.MetaData.Namespace, options)] = *modifyedObj
+	k.objects.set(k.key(obj.Kind, obj.MetaData.Name, obj.MetaData.Namespace, options), *modifyedObj)
 	return modifyedObj, nil
 }
 
 // List -
 func (k K8sInMemory) List(kind string, options *Options, listOptions *ListOptions) (*Object, error) {
-	return nil, errors.New("Not implemented")
+	kind = strings.TrimSuffix(kindWithoutGroup(kind), "s")
+	namespace := k.namespace
+	if options != nil && options.Namespace != "" {
+		namespace = options.Namespace
+	}
+	items := []Object{}
+	for key, obj := range k.objects.all() {
+		if strings.ToLower(obj.Kind) != kind {
+			continue
+		}
+		if isNameSpaced(kind) && !listOptions.AllNamespaces && !strings.HasPrefix(key, namespace+"/") {
+			continue
+		}
+		if listOptions.LabelSelector != nil && !listOptions.LabelSelector.Matches(labels.Set(obj.MetaData.Labels)) {
+			continue
+		}
+		if obj.MetaData.Namespace == "" && isNameSpaced(kind) {
+			obj.MetaData.Namespace = strings.SplitN(key, "/", 2)[0]
+		}
+		items = append(items, obj)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].MetaData.Namespace+"/"+items[i].MetaData.Name < items[j].MetaData.Namespace+"/"+items[j].MetaData.Name
+	})
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	return &Object{Kind: "List", Additional: map[string]json.RawMessage{"items": data}}, nil
 }
 
 // IsNotExist -
@@ -172,8 +257,13 @@ func (k K8sInMemory) ForConfig(config string) (K8s, error) {
 	return k, nil
 }
 
+// kindWithoutGroup strips the group of kinds like deployment.apps, objects are stored by kind only
+func kindWithoutGroup(kind string) string {
+	return strings.SplitN(strings.ToLower(kind), ".", 2)[0]
+}
+
 func (k K8sInMemory) key(kind, name, namespace string, options *Options) string {
-	kind = strings.ToLower(kind)
+	kind = kindWithoutGroup(kind)
 	if isNameSpaced(kind) {
 		if len(namespace) != 0 {
 			return fmt.Sprintf("%s/%s/%s", namespace, kind, name)
@@ -188,12 +278,9 @@ func (k K8sInMemory) key(kind, name, namespace string, options *Options) string
 
 // GetObject -
 func (k K8sInMemory) GetObject(kind string, name string, options *Options) (*Object, error) {
-	obj, ok := k.objects[k.key(kind, name, "", options)]
+	obj, ok := k.objects.get(k.key(kind, name, "", options))
 	if !ok {
-		keys := []string{}
-		for k := range k.objects {
-			keys = append(keys, k)
-		}
+		keys := k.objects.keys()
 		if options != nil && options.IgnoreNotFound {
 			return nil, nil
 		}
@@ -215,12 +302,12 @@ func (k K8sInMemory) CreateOrUpdate(obj *Object, mutate func(obj *Object) error,
 	if err != nil {
 		return nil, err
 	}
-	k.objects[k.key(obj.Kind, obj.MetaData.Name, obj.MetaData.Namespace, options)] = *obj
+	k.objects.set(k.key(obj.Kind, obj.MetaData.Name, obj.MetaData.Namespace, options), *obj)
 	return obj, nil
 }
 
 func (k K8sInMemory) DeleteByName(kind string, name string, options *Options) error {
-	delete(k.objects, k.key(kind, name, "", options))
+	k.objects.delete(k.key(kind, name, "", options))
 	return nil
 }
 
@@ -231,3 +318,8 @@ func (k K8sInMemory) Progress(progress int) {
 func (k K8sInMemory) Namespace(options *Options) *string {
 	return &options.Namespace
 }
+
+// KappAppChanges -
+func (k K8sInMemory) KappAppChanges(options *Options) ([]map[string]string, error) {
+	return []map[string]string{}, nil
+}
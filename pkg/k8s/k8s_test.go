@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"os/exec"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	. "github.com/onsi/ginkgo"
@@ -62,6 +63,48 @@ var _ = Describe("k8s", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(cmdArgs).To(ContainElements("-n", "default"))
 		})
+		It("passes kapp options", func() {
+			k8s := k8s
+			k8s.kappOptions = KappOptions{DiffChanges: true, ApplyDefaultUpdateStrategy: "fallback-on-replace", WaitTimeout: time.Minute, AppChangesMaxToKeep: 3}
+			err := k8s.Apply(func(writer ObjectConsumer) error { return nil }, &Options{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmdArgs).To(ContainElements("--diff-changes", "--apply-default-update-strategy", "fallback-on-replace", "--wait-timeout", "60s", "--app-changes-max-to-keep", "3", "--tty=false"))
+			err = k8s.Delete(func(writer ObjectConsumer) error { return nil }, &Options{Timeout: 10 * time.Second})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmdArgs).To(ContainElements("--wait-timeout", "10s"))
+			Expect(cmdArgs).NotTo(ContainElement("--apply-default-update-strategy"))
+		})
+		It("parses kapp output", func() {
+			Expect(parseKappLine("12:00:01PM: ---- waiting on 3 changes [1/4 done] ----")).To(Equal(&kappEvent{Type: "waiting on", Done: 1, Total: 4}))
+			Expect(parseKappLine("12:00:02PM: ok: reconcile deployment/frontend (apps/v1) namespace: default")).To(Equal(&kappEvent{Type: "ok", Op: "reconcile", Resource: "deployment/frontend"}))
+			Expect(parseKappLine("12:00:03PM: ---- waiting complete [4/4 done] ----")).To(Equal(&kappEvent{Type: "waiting complete", Done: 4, Total: 4}))
+			Expect(parseKappLine("Succeeded")).To(BeNil())
+		})
+		It("reports progress from kapp output", func() {
+			var matched, count int
+			writer, _ := prepareKapp(func(writer ObjectConsumer) error { return nil }, false, func(obj *Object) *Object { return obj }, func(m int, c int) { matched, count = m, c })
+			writer.Write([]byte("---- waiting on 2 changes [0/2 done] ----\nok: reconcile deployment/frontend (apps/v1) namespace: default\n"))
+			Expect(matched).To(Equal(1))
+			Expect(count).To(Equal(2))
+		})
+		It("lists app changes", func() {
+			k8s := k8sImpl{command: func(_ context.Context, name string, arg ...string) *exec.Cmd {
+				cmdArgs = arg
+				return exec.Command("echo", `{"Tables":[{"Rows":[{"name":"app-change-1","successful":"true"}]}],"Lines":["Succeeded"]}`)
+			}, Configs: Configs{tool: ToolKapp}, ctx: context.Background(), app: "app"}
+			changes, err := k8s.KappAppChanges(&Options{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmdArgs).To(ContainElements("app-change", "list", "--json", "-a", "app"))
+			Expect(changes).To(Equal([]map[string]string{{"name": "app-change-1", "successful": "true"}}))
+			_, err = parseKappTable([]byte("invalid"))
+			Expect(err).To(HaveOccurred())
+			cmdArgs = nil
+			k8s.tool = ToolKubectl
+			changes, err = k8s.KappAppChanges(&Options{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(BeEmpty())
+			Expect(cmdArgs).To(BeNil())
+		})
 	})
 	Context("kubectl", func() {
 
@@ -98,6 +141,15 @@ var _ = Describe("k8s", func() {
 			err := k8s.RolloutStatus("kind", "name", &Options{})
 			Expect(err).NotTo(HaveOccurred())
 		})
+		It("rollout status stops if the context is done", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			k8s := k8sImpl{command: func(ctx context.Context, name string, arg ...string) *exec.Cmd {
+				return exec.CommandContext(ctx, "sh", "-c", "echo NotFound >&2; exit 1")
+			}, app: "app", ctx: ctx}
+			err := k8s.RolloutStatus("deployment", "name", &Options{Quiet: true})
+			Expect(err).To(MatchError("cancelled in chart app while doing rollout status deployment/name: context deadline exceeded"))
+		})
 		It("for namespace works", func() {
 			Expect(k2.(*k8sImpl).namespace).To(Equal("ns"))
 		})
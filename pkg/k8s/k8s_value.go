@@ -1,6 +1,7 @@
 package k8s
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -41,6 +42,7 @@ type k8sWatcher struct {
 type k8sWatcherIterator struct {
 	next   chan *Object
 	cancel chan struct{}
+	ctx    context.Context
 }
 
 var (
@@ -230,6 +232,20 @@ func (k *k8sValueImpl) Attr(name string) (starlark.Value, error) {
 			}), nil
 
 		}
+	case "kapp_app_changes":
+		{
+			return starlark.NewBuiltin("kapp_app_changes", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
+				k8sOptions := &Options{}
+				if err := k8sOptions.UnpackArgs("kapp_app_changes", args, kwargs); err != nil {
+					return nil, err
+				}
+				changes, err := k.KappAppChanges(k8sOptions)
+				if err != nil {
+					return starlark.None, err
+				}
+				return starutils.ToStarlark(changes), nil
+			}), nil
+		}
 	case "progress":
 		return k.progressFunction()
 	case "host":
@@ -291,8 +307,13 @@ func (w *k8sWatcher) Type() string          { return "k8sWatcher" }
 func (w *k8sWatcher) Truth() starlark.Bool  { return true }
 func (w *k8sWatcher) Hash() (uint32, error) { return 0, fmt.Errorf("k8sWatcher is unhashable") }
 func (w *k8sWatcher) Iterate() starlark.Iterator {
-	i := &k8sWatcherIterator{next: make(chan *Object, 1), cancel: make(chan struct{}, 1)}
+	ctx := w.k8s.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	i := &k8sWatcherIterator{next: make(chan *Object, 1), cancel: make(chan struct{}, 1), ctx: ctx}
 	go func() {
+		defer close(i.next)
 		stream := w.k8s.Watch(w.kind, w.name, w.options)
 		writer := func(obj *Object) error {
 			select {
@@ -307,10 +328,18 @@ func (w *k8sWatcher) Iterate() starlark.Iterator {
 	return i
 }
 
+// Next - stops iterating if the watch ends or the context is done
 func (i *k8sWatcherIterator) Next(p *starlark.Value) bool {
-	obj := <-i.next
-	*p = starutils.WrapDict(starutils.ToStarlark(obj))
-	return true
+	select {
+	case obj, ok := <-i.next:
+		if !ok {
+			return false
+		}
+		*p = starutils.WrapDict(starutils.ToStarlark(obj))
+		return true
+	case <-i.ctx.Done():
+		return false
+	}
 }
 
 func (i *k8sWatcherIterator) Done() {
@@ -1,6 +1,7 @@
 package k8s
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"time"
@@ -141,6 +142,36 @@ var _ = Describe("K8sValue", func() {
 		Expect(val).To(Equal(starlark.String("value")))
 	})
 
+	It("stops watching if the watch ends or the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		fake := &FakeK8s{
+			WatchStub: func(kind string, name string, options *Options) ObjectStream {
+				return func(w ObjectConsumer) error {
+					return w(&Object{})
+				}
+			},
+		}
+		fake.ContextReturns(ctx)
+		k8s := &k8sValueImpl{fake}
+		watch, _ := k8s.Attr("watch")
+		value, err := starlark.Call(&starlark.Thread{}, watch, starlark.Tuple{starlark.String("kind"), starlark.String("object")}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		iterator := value.(starlark.Iterable).Iterate()
+		var obj starlark.Value
+		Expect(iterator.Next(&obj)).To(BeTrue())
+		Expect(iterator.Next(&obj)).To(BeFalse())
+
+		fake.WatchStub = func(kind string, name string, options *Options) ObjectStream {
+			return func(w ObjectConsumer) error {
+				<-ctx.Done()
+				return nil
+			}
+		}
+		iterator = value.(starlark.Iterable).Iterate()
+		cancel()
+		Expect(iterator.Next(&obj)).To(BeFalse())
+	})
+
 	It("applies objects", func() {
 		var appliedObject Object
 		fake := &FakeK8s{
@@ -163,6 +194,22 @@ var _ = Describe("K8sValue", func() {
 		Expect(appliedObject.MetaData.Name).To(Equal(o.MetaData.Name))
 	})
 
+	It("lists kapp app changes", func() {
+		fake := &FakeK8s{
+			KappAppChangesStub: func(options *Options) ([]map[string]string, error) {
+				return []map[string]string{{"name": "app-change-1"}}, nil
+			},
+		}
+		k8s := &k8sValueImpl{fake}
+		thread := &starlark.Thread{}
+		changes, err := k8s.Attr("kapp_app_changes")
+		Expect(err).NotTo(HaveOccurred())
+		value, err := starlark.Call(thread, changes, nil, []starlark.Tuple{{starlark.String("namespace"), starlark.String("ns")}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value.(starlark.Tuple).Len()).To(Equal(1))
+		Expect(fake.KappAppChangesArgsForCall(0).Namespace).To(Equal("ns"))
+	})
+
 	It("applies stream", func() {
 		var appliedObject Object
 		fake := &FakeK8s{
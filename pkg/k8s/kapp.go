@@ -0,0 +1,147 @@
+package k8s
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// KappOptions options passed to kapp, if kapp is used as tool
+type KappOptions struct {
+	DiffChanges                bool
+	ApplyDefaultUpdateStrategy string
+	WaitTimeout                time.Duration
+	WaitCheckInterval          time.Duration
+	AppChangesMaxToKeep        int
+}
+
+// AddFlags -
+func (o *KappOptions) AddFlags(flagsSet *pflag.FlagSet) {
+	flagsSet.BoolVar(&o.DiffChanges, "kapp-diff-changes", false, "Show kapp resource diffs")
+	flagsSet.StringVar(&o.ApplyDefaultUpdateStrategy, "kapp-apply-default-update-strategy", "", "Change default update strategy of kapp (e.g. fallback-on-replace)")
+	flagsSet.DurationVar(&o.WaitTimeout, "kapp-wait-timeout", 0, "Maximum amount of time kapp waits for resources (0 means kapp default)")
+	flagsSet.DurationVar(&o.WaitCheckInterval, "kapp-wait-check-interval", 0, "Amount of time kapp sleeps between checks while waiting (0 means kapp default)")
+	flagsSet.IntVar(&o.AppChangesMaxToKeep, "kapp-app-changes-max-to-keep", 0, "Maximum number of app changes kapp keeps (0 means kapp default)")
+}
+
+func (o *KappOptions) flags(command string, options *Options) []string {
+	flags := []string{}
+	switch command {
+	case "deploy", "delete":
+	default:
+		return flags
+	}
+	if o.DiffChanges {
+		flags = append(flags, "--diff-changes")
+	}
+	if command == "deploy" && o.ApplyDefaultUpdateStrategy != "" {
+		flags = append(flags, "--apply-default-update-strategy", o.ApplyDefaultUpdateStrategy)
+	}
+	if options.Timeout > 0 {
+		flags = append(flags, "--wait-timeout", fmt.Sprintf("%.0fs", options.Timeout.Seconds()))
+	} else if o.WaitTimeout > 0 {
+		flags = append(flags, "--wait-timeout", fmt.Sprintf("%.0fs", o.WaitTimeout.Seconds()))
+	}
+	if o.WaitCheckInterval > 0 {
+		flags = append(flags, "--wait-check-interval", fmt.Sprintf("%.0fs", o.WaitCheckInterval.Seconds()))
+	}
+	if o.AppChangesMaxToKeep > 0 {
+		flags = append(flags, "--app-changes-max-to-keep", strconv.Itoa(o.AppChangesMaxToKeep))
+	}
+	return flags
+}
+
+// kappEvent is one line of kapp's tty-less progress output
+type kappEvent struct {
+	Type     string
+	Op       string
+	Resource string
+	Done     int
+	Total    int
+}
+
+var kappSectionRegexp = regexp.MustCompile(`----\s+(applying|waiting on)\s+(\d+)\s+changes\s+\[(\d+)/(\d+)\s+done\]`)
+var kappCompleteRegexp = regexp.MustCompile(`----\s+(applying|waiting)\s+complete\s+\[(\d+)/(\d+)\s+done\]`)
+var kappResourceRegexp = regexp.MustCompile(`^(?:[0-9:APM]+:\s+)?(ok|ongoing|fail):\s+(reconcile|delete|noop)\s+(\S+)`)
+
+func parseKappLine(line string) *kappEvent {
+	if match := kappSectionRegexp.FindStringSubmatch(line); match != nil {
+		done, _ := strconv.Atoi(match[3])
+		total, _ := strconv.Atoi(match[4])
+		return &kappEvent{Type: match[1], Done: done, Total: total}
+	}
+	if match := kappCompleteRegexp.FindStringSubmatch(line); match != nil {
+		done, _ := strconv.Atoi(match[2])
+		total, _ := strconv.Atoi(match[3])
+		return &kappEvent{Type: match[1] + " complete", Done: done, Total: total}
+	}
+	if match := kappResourceRegexp.FindStringSubmatch(line); match != nil {
+		return &kappEvent{Type: match[1], Op: match[2], Resource: match[3]}
+	}
+	return nil
+}
+
+func prepareKapp(in ObjectStream, reverse bool, mapper func(obj *Object) *Object, progress func(matched int, count int)) (io.Writer, Stream) {
+	total := 0
+	waited := 0
+	writer := &lineWriter{line: func(line string) {
+		event := parseKappLine(line)
+		if event == nil {
+			return
+		}
+		switch event.Type {
+		case "waiting on", "waiting complete":
+			total = event.Total
+			waited = event.Done
+		case "ok":
+			waited++
+		default:
+			return
+		}
+		progress(waited, total)
+	}}
+	return writer, prepare(in, reverse, mapper)
+}
+
+type kappTable struct {
+	Rows []map[string]string
+}
+
+type kappOutput struct {
+	Tables []kappTable
+	Lines  []string
+}
+
+func parseKappTable(data []byte) ([]map[string]string, error) {
+	var output kappOutput
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&output); err != nil {
+		return nil, fmt.Errorf("Invalid kapp output: %s", err.Error())
+	}
+	result := []map[string]string{}
+	for _, table := range output.Tables {
+		result = append(result, table.Rows...)
+	}
+	return result, nil
+}
+
+// KappAppChanges - lists the app changes recorded by kapp, they are empty if kapp isn't the tool
+func (k *k8sImpl) KappAppChanges(options *Options) ([]map[string]string, error) {
+	if k.tool != ToolKapp {
+		return []map[string]string{}, nil
+	}
+	quiet := *options
+	quiet.Quiet = true
+	cmd := k.kapp("app-change", &quiet, "list", "--json")
+	buffer := &bytes.Buffer{}
+	cmd.Stdout = buffer
+	if err := run(cmd); err != nil {
+		return nil, err
+	}
+	return parseKappTable(buffer.Bytes())
+}
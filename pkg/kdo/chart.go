@@ -9,6 +9,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -26,12 +27,19 @@ import (
 type Chart interface {
 	GetGenus() string
 	GetName() string
+	GetSuffix() string
 	GetVersion() *semver.Version
 	GetNamespace() string
 	Apply(thread *starlark.Thread, k k8s.K8s) error
 	Delete(thread *starlark.Thread, k k8s.K8s, options *DeleteOptions) error
 	Template(thread *starlark.Thread, k k8s.K8s) k8s.Stream
+	Status(thread *starlark.Thread, k k8s.K8s) (*ChartStatus, error)
+	Notes(thread *starlark.Thread, k k8s.K8s) ([]ChartNotes, error)
+	Images(thread *starlark.Thread, k k8s.K8s) ([]ChartImage, error)
+	Inventory(thread *starlark.Thread, k k8s.K8s) (*ChartInventory, error)
 	Package(writer io.Writer, helmFormat bool) error
+	Schema() *Schema
+	Info() *ChartInfo
 	AddUsedBy(reference string, k k8s.K8s) (int, error)
 	RemoveUsedBy(reference string, k k8s.K8s) (int, error)
 }
@@ -44,13 +52,27 @@ type ChartValue interface {
 
 type chartImpl struct {
 	ChartOptions
-	clazz    chartClass
-	Version  semver.Version
-	values   starlark.StringDict
-	methods  map[string]starlark.Callable
-	dir      string
-	repo     Repo
-	initFunc *starlark.Function
+	clazz      chartClass
+	Version    semver.Version
+	values     starlark.StringDict
+	methods    map[string]starlark.Callable
+	dir        string
+	repo       Repo
+	initFunc   *starlark.Function
+	after      []*chartImpl
+	validating bool
+	// initializing is true while init runs
+	initializing bool
+	sources      map[string]string
+	// definedMethods are the names of the functions defined in Chart.star
+	definedMethods []string
+	migrations     []migration
+	// initKwargs are the keyword arguments passed to init, they are persisted together with the explicit values
+	initKwargs []starlark.Tuple
+	// reused are the names of the values reused from the previous apply
+	reused []string
+	// treeRender is set while the objects of the chart tree are post rendered together
+	treeRender *treeRender
 }
 
 var (
@@ -69,6 +91,7 @@ func newChart(thread *starlark.Thread, repo Repo, dir string, opts ...ChartOptio
 	c := &chartImpl{dir: dir, ChartOptions: *co, clazz: chartClass{Name: name}, repo: repo}
 	c.values = make(map[string]starlark.Value)
 	c.methods = make(map[string]starlark.Callable)
+	c.sources = make(map[string]string)
 	hasChartYaml := false
 	if len(co.genus) != 0 {
 		c.clazz.Genus = co.genus
@@ -90,16 +113,35 @@ func newChart(thread *starlark.Thread, repo Repo, dir string, opts ...ChartOptio
 	} else {
 		hasChartYaml = true
 	}
+	if co.reuseFrom != nil {
+		if err := c.reusePersistedValues(co); err != nil {
+			return nil, err
+		}
+	}
 	if err := c.init(thread, hasChartYaml, co); err != nil {
 		return nil, err
 	}
-	c.SetValue(co.properties.GetValue())
+	c.dropUndeclaredValues(co)
+	if err := c.SetValue(co.properties.GetValue()); err != nil {
+		return nil, fmt.Errorf("invalid values for chart %s: %w", c.GetName(), err)
+	}
+	for _, key := range co.properties.GetValue().(*starlark.Dict).Keys() {
+		if name, ok := key.(starlark.String); ok {
+			c.sources[name.GoString()] = co.properties.source(name.GoString())
+		}
+	}
 	return c, nil
 
 }
 
 func (c *chartImpl) builtin(name string, fn func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error)) starlark.Callable {
-	return starlark.NewBuiltin(name+" at "+path.Join(c.dir, "Chart.star"), fn)
+	return starlark.NewBuiltin(name+" at "+path.Join(c.dir, "Chart.star"), c.cancellable(name, fn))
+}
+
+// wrapper creates a builtin which wraps callable. Cancellations are reported using the name of callable.
+func (c *chartImpl) wrapper(name string, callable starlark.Callable, fn func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error)) starlark.Callable {
+	operation := strings.TrimPrefix(strings.SplitN(callable.Name(), " ", 2)[0], "wrap_")
+	return starlark.NewBuiltin(name+" at "+path.Join(c.dir, "Chart.star"), c.cancellable(operation, fn))
 }
 
 func (c *chartImpl) GetGenus() string {
@@ -116,6 +158,10 @@ func (c *chartImpl) GetName() string {
 	return fmt.Sprintf("%s-%s", c.clazz.Name, c.suffix)
 }
 
+func (c *chartImpl) GetSuffix() string {
+	return c.suffix
+}
+
 func (c *chartImpl) GetVersion() *semver.Version {
 	return c.clazz.GetVersion()
 }
@@ -241,6 +287,7 @@ func (c *chartImpl) AttrNames() []string {
 // SetField -
 func (c *chartImpl) SetField(name string, val starlark.Value) error {
 	val = starutils.UnwrapDict(val)
+	c.sources[name] = "Chart.star"
 	existing, ok := c.values[name]
 	if ok {
 		property, ok := existing.(PropertyValue)
@@ -249,13 +296,19 @@ func (c *chartImpl) SetField(name string, val starlark.Value) error {
 			if ok {
 				err := subchart.SetValue(property)
 				if err != nil {
-					return err
+					return validationError(name, err)
 				}
 			} else {
-				return property.SetValue(val)
+				return validationError(name, property.SetValue(val))
 			}
 		}
 	}
+	if property, ok := val.(Property); ok && c.initializing {
+		// init only sees valid explicit values, invalid ones are reported together after init
+		if value := c.properties.get(name); value != starlark.None {
+			_ = property.SetValue(value)
+		}
+	}
 	c.values[name] = val
 	return nil
 }
@@ -288,7 +341,14 @@ func (c *chartImpl) applyFunction() starlark.Callable {
 }
 
 func (c *chartImpl) Apply(thread *starlark.Thread, k k8s.K8s) error {
-	_, err := starlark.Call(thread, c.methods["apply"], starlark.Tuple{k8s.NewK8sValue(k)}, nil)
+	inheritContext(thread, k)
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("invalid values for chart %s: %w", c.GetName(), err)
+	}
+	err := c.withTreeRender(func() error {
+		_, err := starlark.Call(thread, c.methods["apply"], starlark.Tuple{k8s.NewK8sValue(k)}, nil)
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -297,7 +357,7 @@ func (c *chartImpl) Apply(thread *starlark.Thread, k k8s.K8s) error {
 }
 
 func (c *chartImpl) apply(thread *starlark.Thread, k k8s.K8sValue) error {
-	err := c.eachSubChart(func(subChart *chartImpl) error {
+	err := c.eachSubChartOrdered(thread, false, func(thread *starlark.Thread, subChart *chartImpl) error {
 		_, err := starlark.Call(thread, subChart.methods["apply"], starlark.Tuple{k}, nil)
 		return err
 	})
@@ -331,11 +391,46 @@ func (c *chartImpl) applyLocal(thread *starlark.Thread, k k8s.K8sValue, k8sOptio
 		return nil
 	}
 	k8sOptions.ClusterScoped = true
-	return k.Apply(k8s.Decode(c.template(thread, glob, k)), k8sOptions)
+	hooks, err := splitHooks(k8s.Decode(c.template(thread, glob, k)))
+	if err != nil {
+		return err
+	}
+	phase, err := c.hookPhase(k, hooks)
+	if err != nil {
+		return err
+	}
+	if err := hooks.run(k, "pre-"+phase, c.namespace); err != nil {
+		return err
+	}
+	if err := k.Apply(hooks.manifestStream(), k8sOptions); err != nil {
+		return err
+	}
+	return hooks.run(k, "post-"+phase, c.namespace)
 }
 
 func (c *chartImpl) objName() string {
-	return "kdo." + c.GetGenus()
+	return instanceName(c.GetGenus(), c.suffix)
+}
+
+// instanceName is the name of the config map and secret of an installed chart. Charts installed with a suffix are
+// separate instances of the genus.
+func instanceName(genus string, suffix string) string {
+	if suffix == "" {
+		return "kdo." + genus
+	}
+	return "kdo." + genus + "-" + suffix
+}
+
+func (c *chartImpl) labels() map[string]string {
+	labels := map[string]string{
+		"kdo.sap.github.com/chart":   "true",
+		"kdo.sap.github.com/genus":   c.GetGenus(),
+		"kdo.sap.github.com/version": c.GetVersionString(),
+	}
+	if c.suffix != "" {
+		labels[suffixLabel] = c.suffix
+	}
+	return labels
 }
 
 func (c *chartImpl) configMap() *k8s.Object {
@@ -345,11 +440,7 @@ func (c *chartImpl) configMap() *k8s.Object {
 		MetaData: k8s.MetaData{
 			Name:      c.objName(),
 			Namespace: c.namespace,
-			Labels: map[string]string{
-				"kdo.sap.github.com/chart":   "true",
-				"kdo.sap.github.com/genus":   c.GetGenus(),
-				"kdo.sap.github.com/version": c.GetVersionString(),
-			},
+			Labels:    c.labels(),
 			Annotations: map[string]string{
 				"kapp.k14s.io/disable-original": "true",
 			},
@@ -364,11 +455,7 @@ func (c *chartImpl) secret() *k8s.Object {
 		MetaData: k8s.MetaData{
 			Name:      c.objName(),
 			Namespace: c.namespace,
-			Labels: map[string]string{
-				"kdo.sap.github.com/chart":   "true",
-				"kdo.sap.github.com/genus":   c.GetGenus(),
-				"kdo.sap.github.com/version": c.GetVersionString(),
-			},
+			Labels:    c.labels(),
 			Annotations: map[string]string{
 				"kapp.k14s.io/disable-original": "true",
 			},
@@ -381,11 +468,15 @@ func (c *chartImpl) modifyConfigMap(obj *k8s.Object) error {
 	if err := c.Package(buffer, false); err != nil {
 		return err
 	}
-	data, err := json.Marshal(map[string]string{
+	values := map[string]string{
 		"genus":   c.GetGenus(),
 		"version": c.GetVersion().String(),
 		"chart":   base64.StdEncoding.EncodeToString(buffer.Bytes()),
-	})
+	}
+	if c.suffix != "" {
+		values["suffix"] = c.suffix
+	}
+	data, err := json.Marshal(values)
 	if err != nil {
 		return err
 	}
@@ -396,7 +487,7 @@ func (c *chartImpl) modifyConfigMap(obj *k8s.Object) error {
 }
 func (c *chartImpl) modifySecret(obj *k8s.Object) error {
 	byteData := map[string][]byte{}
-	// only persist properties
+	// only persist properties and the arguments of init
 	for _, t := range c.GetValue().(starlark.IterableMapping).Items() {
 		j, err := json.Marshal(starutils.ToGo(t.Index(1)))
 		if err != nil {
@@ -404,19 +495,45 @@ func (c *chartImpl) modifySecret(obj *k8s.Object) error {
 		}
 		byteData[t.Index(0).(starlark.String).GoString()] = j
 	}
-	data, err := json.Marshal(byteData)
-	if err != nil {
+	explicit := []string{}
+	for _, key := range c.properties.GetValue().(*starlark.Dict).Keys() {
+		explicit = append(explicit, key.(starlark.String).GoString())
+	}
+	for _, kwarg := range c.initKwargs {
+		name := kwarg.Index(0).(starlark.String).GoString()
+		explicit = append(explicit, name)
+		if _, ok := byteData[name]; ok {
+			continue
+		}
+		j, err := json.Marshal(starutils.ToGo(kwarg.Index(1)))
+		if err != nil {
+			return err
+		}
+		byteData[name] = j
+	}
+	// values assigned by the chart itself may change with the next version, --reuse-values only reuses these
+	sort.Strings(explicit)
+	if obj.MetaData.Annotations == nil {
+		obj.MetaData.Annotations = map[string]string{}
+	}
+	obj.MetaData.Annotations[explicitValuesAnnotation] = strings.Join(explicit, ",")
+	if err := c.checkEncryption(obj); err != nil {
 		return err
 	}
-	obj.Additional = map[string]json.RawMessage{
-		"data": data,
+	provider, err := c.encryption()
+	if err != nil {
+		return err
 	}
-	return nil
+	return setSecretValues(obj, byteData, provider)
 }
 
 func (c *chartImpl) Delete(thread *starlark.Thread, k k8s.K8s, options *DeleteOptions) error {
 	thread.SetLocal("delete-options", options)
-	_, err := starlark.Call(thread, c.methods["delete"], starlark.Tuple{k8s.NewK8sValue(k)}, nil)
+	inheritContext(thread, k)
+	err := c.withTreeRender(func() error {
+		_, err := starlark.Call(thread, c.methods["delete"], starlark.Tuple{k8s.NewK8sValue(k)}, nil)
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -435,7 +552,7 @@ func (c *chartImpl) deleteFunction() starlark.Callable {
 }
 
 func (c *chartImpl) delete(thread *starlark.Thread, k k8s.K8sValue) error {
-	err := c.eachSubChart(func(subChart *chartImpl) error {
+	err := c.eachSubChartOrdered(thread, true, func(thread *starlark.Thread, subChart *chartImpl) error {
 		_, err := starlark.Call(thread, subChart.methods["delete"], starlark.Tuple{k}, nil)
 		return err
 	})
@@ -462,10 +579,19 @@ func (c *chartImpl) deleteLocal(thread *starlark.Thread, k k8s.K8sValue, k8sOpti
 		return nil
 	}
 	k8sOptions.ClusterScoped = true
-	err := k.Delete(k8s.Decode(c.template(thread, glob, k)), k8sOptions)
+	hooks, err := splitHooks(k8s.Decode(c.template(thread, glob, k)))
 	if err != nil {
 		return err
 	}
+	if err := hooks.run(k, "pre-delete", c.namespace); err != nil {
+		return err
+	}
+	if err := k.Delete(hooks.manifestStream(), k8sOptions); err != nil {
+		return err
+	}
+	if err := hooks.run(k, "post-delete", c.namespace); err != nil {
+		return err
+	}
 	vault := &vaultK8s{k8s: k, namespace: c.namespace}
 	return c.eachJewel(func(v *jewel) error {
 		return v.delete(vault)
@@ -473,8 +599,8 @@ func (c *chartImpl) deleteLocal(thread *starlark.Thread, k k8s.K8sValue, k8sOpti
 }
 
 func (c *chartImpl) eachSubChart(block func(subChart *chartImpl) error) error {
-	for _, v := range c.values {
-		subChart, ok := v.(*chartImpl)
+	for _, key := range c.sortedKeys() {
+		subChart, ok := c.values[key].(*chartImpl)
 		if ok {
 			err := block(subChart)
 			if err != nil {
@@ -486,7 +612,7 @@ func (c *chartImpl) eachSubChart(block func(subChart *chartImpl) error) error {
 }
 
 func (c *chartImpl) wrapApply(callable starlark.Callable) starlark.Callable {
-	return c.builtin("wrap_apply", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
+	return c.wrapper("wrap_apply", callable, func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
 		if len(args) == 0 {
 			return nil, fmt.Errorf("Missing first argument k8s")
 		}
@@ -494,36 +620,58 @@ func (c *chartImpl) wrapApply(callable starlark.Callable) starlark.Callable {
 		if !ok {
 			return nil, fmt.Errorf("Invalid first argument to %s", callable.Name())
 		}
-		for _, v := range c.values {
-			dependency, ok := v.(*dependency)
-			if ok {
-				err := dependency.Apply(thread, k)
-				if err != nil {
-					return starlark.None, err
-				}
+		value, err := c.applyWithHooks(thread, k, callable, args, kwargs)
+		return value, c.onError(thread, k, err)
+	})
+}
+
+func (c *chartImpl) applyWithHooks(thread *starlark.Thread, k k8s.K8sValue, callable starlark.Callable, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	for _, key := range c.sortedKeys() {
+		dependency, ok := c.values[key].(*dependency)
+		if ok {
+			err := dependency.Apply(thread, k)
+			if err != nil {
+				return starlark.None, err
 			}
 		}
-		value, err := starlark.Call(thread, callable, args, kwargs)
+	}
+	if !c.skipChart {
+		secret, err := k.Get("secret", c.objName(), &k8s.Options{Namespace: c.namespace, IgnoreNotFound: true, Quiet: true})
 		if err != nil {
-			return value, err
+			return starlark.None, err
 		}
-		if !c.skipChart {
-			_, err = k.CreateOrUpdate(c.configMap(), c.modifyConfigMap, &k8s.Options{Quiet: true})
-			if err != nil {
-				return starlark.None, nil
-			}
-			_, err = k.CreateOrUpdate(c.secret(), c.modifySecret, &k8s.Options{Quiet: true})
-			if err != nil {
-				return starlark.None, nil
-			}
+		if err := c.checkEncryption(secret); err != nil {
+			return starlark.None, err
 		}
+	}
+	if err := c.upgrade(thread, k); err != nil {
+		return starlark.None, err
+	}
+	if err := c.hook(thread, "pre_apply", k); err != nil {
+		return starlark.None, err
+	}
+	value, err := starlark.Call(thread, callable, args, kwargs)
+	if err != nil {
 		return value, err
-
-	})
+	}
+	if err := c.hook(thread, "post_apply", k); err != nil {
+		return starlark.None, err
+	}
+	if !c.skipChart {
+		_, err = k.CreateOrUpdate(c.configMap(), c.modifyConfigMap, &k8s.Options{Quiet: true})
+		if err != nil {
+			return starlark.None, nil
+		}
+		_, err = k.CreateOrUpdate(c.secret(), c.modifySecret, &k8s.Options{Quiet: true})
+		if err != nil {
+			return starlark.None, nil
+		}
+	}
+	return value, err
 }
 
 func (c *chartImpl) wrapDelete(callable starlark.Callable) starlark.Callable {
-	return c.builtin("wrap_delete", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return c.wrapper("wrap_delete", callable, func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		l := thread.Local("delete-options")
 		var deleteOptions *DeleteOptions
 		if l != nil {
@@ -538,48 +686,82 @@ func (c *chartImpl) wrapDelete(callable starlark.Callable) starlark.Callable {
 		if !ok {
 			return starlark.None, fmt.Errorf("Invalid first argument to %s", callable.Name())
 		}
-		if !deleteOptions.force {
-			obj, err := k.Get("configmap", c.objName(), &k8s.Options{IgnoreNotFound: true, Quiet: true})
-			if err != nil {
-				return starlark.None, err
-			}
-			if obj != nil {
-				if remainingReferences(obj) > 0 {
-					return starlark.None, fmt.Errorf("Can't delete %s in namespace %s, because it's still used by other charts", c.GetName(), c.namespace)
-				}
-			}
+		value, err := c.deleteWithHooks(thread, k, deleteOptions, callable, args, kwargs)
+		return value, c.onError(thread, k, err)
+	})
+}
+
+func (c *chartImpl) deleteWithHooks(thread *starlark.Thread, k k8s.K8sValue, deleteOptions *DeleteOptions, callable starlark.Callable, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if !deleteOptions.force {
+		obj, err := k.Get("configmap", c.objName(), &k8s.Options{IgnoreNotFound: true, Quiet: true})
+		if err != nil {
+			return starlark.None, err
 		}
-		if !c.skipChart {
-			for _, obj := range []*k8s.Object{c.configMap(), c.secret()} {
-				err := k.DeleteByName(obj.Kind, obj.MetaData.Name, &k8s.Options{IgnoreNotFound: true, Quiet: true})
-				if err != nil {
-					return starlark.None, err
-				}
+		if obj != nil {
+			if remainingReferences(obj) > 0 {
+				return starlark.None, fmt.Errorf("Can't delete %s in namespace %s, because it's still used by other charts", c.GetName(), c.namespace)
 			}
 		}
-		value, err := starlark.Call(thread, callable, args, kwargs)
-		if err != nil {
-			return value, err
+	}
+	if err := c.hook(thread, "pre_delete", k); err != nil {
+		return starlark.None, err
+	}
+	if !c.skipChart {
+		for _, obj := range []*k8s.Object{c.configMap(), c.secret()} {
+			err := k.DeleteByName(obj.Kind, obj.MetaData.Name, &k8s.Options{IgnoreNotFound: true, Quiet: true})
+			if err != nil {
+				return starlark.None, err
+			}
 		}
+	}
+	value, err := starlark.Call(thread, callable, args, kwargs)
+	if err != nil {
+		return value, err
+	}
 
-		for _, v := range c.values {
-			dependency, ok := v.(*dependency)
-			if ok {
-				err := dependency.Delete(thread, k, deleteOptions)
-				if err != nil {
-					return starlark.None, err
-				}
+	for _, key := range c.sortedKeys() {
+		dependency, ok := c.values[key].(*dependency)
+		if ok {
+			err := dependency.Delete(thread, k, deleteOptions)
+			if err != nil {
+				return starlark.None, err
 			}
 		}
-		thread.SetLocal("delete-options", deleteOptions)
-		return value, nil
+	}
+	thread.SetLocal("delete-options", deleteOptions)
+	if err := c.hook(thread, "post_delete", k); err != nil {
+		return starlark.None, err
+	}
+	return value, nil
+}
 
-	})
+// hook calls the method with the given name, if it's defined in Chart.star
+func (c *chartImpl) hook(thread *starlark.Thread, name string, args ...starlark.Value) error {
+	method, ok := c.methods[name]
+	if !ok {
+		return nil
+	}
+	_, err := starlark.Call(thread, method, args, nil)
+	return err
+}
+
+// onError passes err to the on_error method, if it's defined in Chart.star. err is returned unchanged unless on_error fails, too.
+func (c *chartImpl) onError(thread *starlark.Thread, k k8s.K8sValue, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, cancelled := err.(*k8s.CancelledError); cancelled {
+		return err
+	}
+	if hookErr := c.hook(thread, "on_error", k, starlark.String(err.Error())); hookErr != nil {
+		return fmt.Errorf("%s\non_error of chart %s failed: %s", err.Error(), c.GetName(), hookErr.Error())
+	}
+	return err
 }
 
 func (c *chartImpl) eachJewel(block func(x *jewel) error) error {
-	for _, val := range c.values {
-		v, ok := val.(*jewel)
+	for _, key := range c.sortedKeys() {
+		v, ok := c.values[key].(*jewel)
 		if ok {
 			err := block(v)
 			if err != nil {
@@ -592,8 +774,8 @@ func (c *chartImpl) eachJewel(block func(x *jewel) error) error {
 
 func (c *chartImpl) getValue(getter func(ReadProperty) starlark.Value) *starlark.Dict {
 	result := starlark.NewDict(0)
-	for n, val := range c.values {
-		property, ok := val.(ReadProperty)
+	for _, n := range c.sortedKeys() {
+		property, ok := c.values[n].(ReadProperty)
 		if ok {
 			value := getter(property)
 			if value.Truth() {
@@ -621,17 +803,34 @@ func (c *chartImpl) SetValue(value starlark.Value) error {
 	if !ok {
 		return fmt.Errorf("value must be a dict not %s", value.String())
 	}
+	errs := &ValidationError{}
 	for _, t := range m.Items() {
 		key, ok := t.Index(0).(starlark.String)
 		if ok {
 			err := c.SetField(key.GoString(), t.Index(1))
 			if err != nil {
-				return err
+				errs.add("", err)
 			}
 		}
 	}
-	return nil
+	return errs.orNil()
+}
 
+// validate reports required properties of the chart and its subcharts without value
+func (c *chartImpl) validate() error {
+	if c.validating {
+		return nil
+	}
+	c.validating = true
+	defer func() { c.validating = false }()
+	errs := &ValidationError{}
+	if err := validateValues(c.values); err != nil {
+		errs.add("", err)
+	}
+	if err := c.validateSchema(); err != nil {
+		errs.add("", err)
+	}
+	return errs.orNil()
 }
 
 func remainingReferences(obj *k8s.Object) int {
@@ -651,6 +850,18 @@ func usedByAnnotation(reference string) string {
 	return "kdo-usedby-" + k8s.FixLabelValue(reference)
 }
 
+// usedByReference identifies the instance of this chart with the given suffix as user of a dependency
+func (c *chartImpl) usedByReference(suffix string) string {
+	genus := c.genus
+	if genus == "" {
+		genus = c.GetGenus()
+	}
+	if suffix == "" {
+		return fmt.Sprintf("%s-%s", c.namespace, genus)
+	}
+	return fmt.Sprintf("%s-%s-%s", c.namespace, genus, suffix)
+}
+
 func (c *chartImpl) AddUsedBy(reference string, k k8s.K8s) (int, error) {
 	patch := fmt.Sprintf(`[{"op": "add", "path": "/metadata/annotations/%s", "value" : "True"}]`, usedByAnnotation(reference))
 	obj, err := k.Patch("configmap", c.objName(), types.JSONPatchType, patch, &k8s.Options{Namespace: c.namespace})
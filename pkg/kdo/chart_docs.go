@@ -0,0 +1,157 @@
+package kdo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// docsHeader marks generated documentation
+const docsHeader = "<!-- generated by kdo docs, do not edit -->"
+
+// Markdown writes the reference documentation of the chart
+func (i *ChartInfo) Markdown(w io.Writer) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%s\n\n# %s\n\n", docsHeader, i.Name)
+	if i.Class.Description != "" {
+		fmt.Fprintf(b, "%s\n\n", i.Class.Description)
+	}
+	rows := [][]string{}
+	for _, row := range [][]string{{"Version", i.Class.Version}, {"App version", i.Class.AppVersion}, {"Home", i.Class.Home}, {"Kube version", i.Class.KubeVersion}} {
+		if row[1] != "" {
+			rows = append(rows, row)
+		}
+	}
+	if len(i.Class.Maintainers) != 0 {
+		names := make([]string, 0)
+		for _, m := range i.Class.Maintainers {
+			names = append(names, fmt.Sprint(m["name"]))
+		}
+		rows = append(rows, []string{"Maintainers", strings.Join(names, ", ")})
+	}
+	markdownTable(b, []string{"Attribute", "Value"}, rows)
+	if doc := strings.TrimSpace(i.Doc); doc != "" {
+		fmt.Fprintf(b, "## Usage\n\n%s\n\n", doc)
+	}
+	if len(i.Params) != 0 {
+		rows = [][]string{}
+		for _, p := range i.Params {
+			dflt := ""
+			if !p.Required {
+				dflt = markdownValue(p.Default)
+			}
+			rows = append(rows, []string{"`" + p.Name + "`", dflt, markdownBool(p.Required)})
+		}
+		b.WriteString("## Parameters\n\n")
+		markdownTable(b, []string{"Name", "Default", "Required"}, rows)
+	}
+	if len(i.Properties) != 0 {
+		b.WriteString("## Properties\n\n")
+		markdownTable(b, []string{"Name", "Type", "Default", "Required", "Description"}, propertyRows("", i.Properties))
+	}
+	if len(i.Methods) != 0 {
+		b.WriteString("## Methods\n\n")
+		for _, m := range i.Methods {
+			fmt.Fprintf(b, "* `%s`\n", m)
+		}
+		b.WriteString("\n")
+	}
+	if len(i.Jewels) != 0 {
+		rows = [][]string{}
+		for _, j := range i.Jewels {
+			rows = append(rows, []string{"`" + j.Attribute + "`", j.Type, "`" + j.Secret + "`", strings.Join(j.Keys, ", ")})
+		}
+		b.WriteString("## Jewels\n\n")
+		markdownTable(b, []string{"Attribute", "Type", "Secret", "Keys"}, rows)
+	}
+	if len(i.Dependencies) != 0 {
+		rows = [][]string{}
+		for _, d := range i.Dependencies {
+			rows = append(rows, []string{"`" + d.Attribute + "`", d.URL, "`" + d.Constraint + "`", d.Namespace})
+		}
+		b.WriteString("## Dependencies\n\n")
+		markdownTable(b, []string{"Attribute", "Chart", "Constraint", "Namespace"}, rows)
+	}
+	if len(i.Subcharts) != 0 {
+		b.WriteString("## Subcharts\n\n")
+		subchartTree(b, i.Subcharts, "")
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, strings.TrimRight(b.String(), "\n")+"\n")
+	return err
+}
+
+func propertyRows(prefix string, properties []PropertyInfo) [][]string {
+	rows := [][]string{}
+	for _, p := range properties {
+		description := p.Description
+		constraints := []string{}
+		if len(p.Choices) != 0 {
+			constraints = append(constraints, "one of "+markdownValue(p.Choices))
+		}
+		if p.Pattern != "" {
+			constraints = append(constraints, "pattern `"+p.Pattern+"`")
+		}
+		if p.Min != nil {
+			constraints = append(constraints, "min "+markdownValue(p.Min))
+		}
+		if p.Max != nil {
+			constraints = append(constraints, "max "+markdownValue(p.Max))
+		}
+		if len(constraints) != 0 {
+			description = strings.TrimSpace(description + " (" + strings.Join(constraints, ", ") + ")")
+		}
+		rows = append(rows, []string{"`" + prefix + p.Name + "`", p.Type, markdownValue(p.Default), markdownBool(p.Required), description})
+		rows = append(rows, propertyRows(prefix+p.Name+".", p.Properties)...)
+	}
+	return rows
+}
+
+func subchartTree(b *strings.Builder, subcharts []SubchartInfo, indent string) {
+	for _, s := range subcharts {
+		fmt.Fprintf(b, "%s* `%s`: %s\n", indent, s.Attribute, strings.TrimSpace(s.Name+" "+s.Class.Version))
+		subchartTree(b, s.Subcharts, indent+"  ")
+	}
+}
+
+func markdownTable(b *strings.Builder, header []string, rows [][]string) {
+	if len(rows) == 0 {
+		return
+	}
+	b.WriteString("|")
+	for _, h := range header {
+		b.WriteString(" " + h + " |")
+	}
+	b.WriteString("\n|")
+	for range header {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+	for _, row := range rows {
+		b.WriteString("|")
+		for _, cell := range row {
+			b.WriteString(" " + strings.ReplaceAll(strings.ReplaceAll(cell, "|", "\\|"), "\n", " ") + " |")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+}
+
+func markdownValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return "`" + string(data) + "`"
+}
+
+func markdownBool(value bool) string {
+	if value {
+		return "yes"
+	}
+	return ""
+}
@@ -0,0 +1,33 @@
+package kdo
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Markdown", func() {
+
+	It("documents a chart", func() {
+		info := &ChartInfo{
+			Name:       "example",
+			Class:      chartClass{Version: "1.0.0", Description: "An example"},
+			Doc:        "Installs the example.",
+			Params:     []ParamInfo{{Name: "name", Required: true}},
+			Properties: []PropertyInfo{{Name: "db", Type: "struct", Properties: []PropertyInfo{{Name: "port", Type: "int", Default: 5432, Description: "Port | number", Min: 1}}}},
+			Methods:    []string{"rotate"},
+			Jewels:     []JewelInfo{{Attribute: "admin", Type: "user_credential", Secret: "admin", Keys: []string{"password", "username"}}},
+			Subcharts:  []SubchartInfo{{Attribute: "db", ChartInfo: ChartInfo{Name: "mariadb", Class: chartClass{Version: "10.4"}, Subcharts: []SubchartInfo{{Attribute: "backup", ChartInfo: ChartInfo{Name: "backup"}}}}}},
+		}
+		buf := &bytes.Buffer{}
+		Expect(info.Markdown(buf)).To(Succeed())
+		Expect(buf.String()).To(HavePrefix(docsHeader + "\n\n# example\n\nAn example\n"))
+		Expect(buf.String()).To(ContainSubstring("## Usage\n\nInstalls the example.\n"))
+		Expect(buf.String()).To(ContainSubstring("| `name` |  | yes |\n"))
+		Expect(buf.String()).To(ContainSubstring("| `db.port` | int | `5432` |  | Port \\| number (min `1`) |\n"))
+		Expect(buf.String()).To(ContainSubstring("* `rotate`\n"))
+		Expect(buf.String()).To(ContainSubstring("| `admin` | user_credential | `admin` | password, username |\n"))
+		Expect(buf.String()).To(HaveSuffix("* `db`: mariadb 10.4\n  * `backup`: backup\n"))
+	})
+})
@@ -0,0 +1,216 @@
+package kdo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/k14s/starlark-go/starlark"
+)
+
+// threadLocals are copied to the threads which are used to apply or delete subcharts in parallel
+var threadLocals = []string{"delete-options", contextLocal}
+
+// subChartGraph describes the order in which the subcharts of a chart are applied
+type subChartGraph struct {
+	nodes []*chartImpl
+	edges map[*chartImpl][]*chartImpl
+}
+
+// parseAfter converts the value of the `after` parameter of `chart` into a list of charts
+func parseAfter(value starlark.Value) ([]*chartImpl, error) {
+	switch value := value.(type) {
+	case *chartImpl:
+		return []*chartImpl{value}, nil
+	case *starlark.List, starlark.Tuple:
+		list := value.(starlark.Indexable)
+		result := make([]*chartImpl, 0, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			c, ok := list.Index(i).(*chartImpl)
+			if !ok {
+				return nil, fmt.Errorf("after must be a list of charts not containing %s", list.Index(i).Type())
+			}
+			result = append(result, c)
+		}
+		return result, nil
+	case starlark.NoneType:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("after must be a list of charts not %s", value.Type())
+	}
+}
+
+func (c *chartImpl) sortedKeys() []string {
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// subChartGraph builds the graph of all subcharts. A subchart depends on all subcharts given with `after`
+// and on all subcharts which are referenced by its values. For deletion the graph is reversed.
+func (c *chartImpl) subChartGraph(reverse bool) *subChartGraph {
+	g := &subChartGraph{edges: map[*chartImpl][]*chartImpl{}}
+	siblings := map[*chartImpl]bool{}
+	c.eachSubChart(func(subChart *chartImpl) error {
+		if !siblings[subChart] {
+			siblings[subChart] = true
+			g.nodes = append(g.nodes, subChart)
+		}
+		return nil
+	})
+	addEdge := func(from *chartImpl, to *chartImpl) {
+		if from == to || !siblings[to] {
+			return
+		}
+		if reverse {
+			from, to = to, from
+		}
+		for _, e := range g.edges[from] {
+			if e == to {
+				return
+			}
+		}
+		g.edges[from] = append(g.edges[from], to)
+	}
+	for _, subChart := range g.nodes {
+		for _, after := range subChart.after {
+			addEdge(subChart, after)
+		}
+		for _, key := range subChart.sortedKeys() {
+			walkCharts(subChart.values[key], func(referenced *chartImpl) {
+				addEdge(subChart, referenced)
+			})
+		}
+	}
+	return g
+}
+
+func walkCharts(value starlark.Value, cb func(c *chartImpl)) {
+	switch value := value.(type) {
+	case *chartImpl:
+		cb(value)
+	case *starlark.Dict:
+		for _, item := range value.Items() {
+			walkCharts(item.Index(1), cb)
+		}
+	case *starlark.List:
+		for i := 0; i < value.Len(); i++ {
+			walkCharts(value.Index(i), cb)
+		}
+	case starlark.Tuple:
+		for _, v := range value {
+			walkCharts(v, cb)
+		}
+	}
+}
+
+// order returns the nodes in topological order. Nodes without ordering constraints keep the order of their names.
+func (g *subChartGraph) order() ([]*chartImpl, error) {
+	result := make([]*chartImpl, 0, len(g.nodes))
+	state := map[*chartImpl]int{}
+	var visit func(n *chartImpl, path []string) error
+	visit = func(n *chartImpl, path []string) error {
+		path = append(path, n.GetName())
+		switch state[n] {
+		case 1:
+			return fmt.Errorf("cyclic dependency between charts %s", strings.Join(path, " -> "))
+		case 2:
+			return nil
+		}
+		state[n] = 1
+		for _, d := range g.edges[n] {
+			if err := visit(d, path); err != nil {
+				return err
+			}
+		}
+		state[n] = 2
+		result = append(result, n)
+		return nil
+	}
+	for _, n := range g.nodes {
+		if err := visit(n, nil); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// run calls block for all nodes. A node is started after all nodes it depends on are finished successfully.
+// At most parallelism nodes are running concurrently. The first error stops starting new nodes.
+func (g *subChartGraph) run(parallelism int, block func(subChart *chartImpl) error) error {
+	order, err := g.order()
+	if err != nil {
+		return err
+	}
+	if parallelism <= 1 {
+		for _, n := range order {
+			if err := block(n); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	done := map[*chartImpl]chan struct{}{}
+	for _, n := range order {
+		done[n] = make(chan struct{})
+	}
+	var mutex sync.Mutex
+	var firstErr error
+	failed := func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return firstErr != nil
+	}
+	semaphore := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for _, n := range order {
+		wg.Add(1)
+		go func(n *chartImpl) {
+			defer wg.Done()
+			defer close(done[n])
+			for _, d := range g.edges[n] {
+				<-done[d]
+			}
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			if failed() {
+				return
+			}
+			if err := block(n); err != nil {
+				mutex.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mutex.Unlock()
+			}
+		}(n)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// eachSubChartOrdered calls block for all subcharts honoring their dependencies. If the chart is configured with a
+// parallelism greater than one, independent subcharts are processed concurrently, each on its own starlark thread.
+func (c *chartImpl) eachSubChartOrdered(thread *starlark.Thread, reverse bool, block func(thread *starlark.Thread, subChart *chartImpl) error) error {
+	g := c.subChartGraph(reverse)
+	if c.parallelism <= 1 {
+		return g.run(1, func(subChart *chartImpl) error { return block(thread, subChart) })
+	}
+	return g.run(c.parallelism, func(subChart *chartImpl) error {
+		return block(newSubChartThread(thread, subChart), subChart)
+	})
+}
+
+func newSubChartThread(thread *starlark.Thread, subChart *chartImpl) *starlark.Thread {
+	result := &starlark.Thread{Name: subChart.GetName(), Load: thread.Load, Print: thread.Print}
+	for _, key := range threadLocals {
+		if value := thread.Local(key); value != nil {
+			result.SetLocal(key, value)
+		}
+	}
+	return result
+}
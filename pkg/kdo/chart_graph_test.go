@@ -0,0 +1,124 @@
+package kdo
+
+import (
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("SubChartGraph", func() {
+	var dir TestDir
+	var repo Repo
+	thread := &starlark.Thread{Name: "main"}
+
+	names := func(charts []*chartImpl) []string {
+		result := []string{}
+		for _, c := range charts {
+			result = append(result, c.GetName())
+		}
+		return result
+	}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		repo, _ = NewRepo()
+		for _, name := range []string{"db", "app", "cache", "web"} {
+			dir.MkdirAll(name+"/templates", 0755)
+			dir.WriteFile(name+"/Chart.yaml", []byte("name: "+name+"\nversion: 1.0.0\n"), 0644)
+			dir.WriteFile(name+"/templates/cm.yaml", []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: "+name+"\n"), 0644)
+		}
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	It("orders subcharts using after and references", func() {
+		dir.WriteFile("Chart.star", []byte(`
+def init(self):
+  self.web = chart("web")
+  self.db = chart("db")
+  self.app = chart("app", after=[self.db])
+  self.cache = chart("cache")
+  self.web.backend = { "app" : self.app }
+`), 0644)
+		c, err := newChart(thread, repo, dir.Root())
+		Expect(err).NotTo(HaveOccurred())
+		order, err := c.subChartGraph(false).order()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(order)).To(Equal([]string{"db", "app", "cache", "web"}))
+		order, err = c.subChartGraph(true).order()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(order)).To(Equal([]string{"web", "app", "cache", "db"}))
+	})
+
+	It("detects cycles", func() {
+		dir.WriteFile("Chart.star", []byte(`
+def init(self):
+  self.db = chart("db")
+  self.app = chart("app", after=[self.db])
+  self.db.app = self.app
+`), 0644)
+		c, err := newChart(thread, repo, dir.Root())
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.subChartGraph(false).order()
+		Expect(err).To(MatchError(ContainSubstring("cyclic dependency between charts")))
+	})
+
+	It("rejects invalid after", func() {
+		dir.WriteFile("Chart.star", []byte(`
+def init(self):
+  self.app = chart("app", after=["db"])
+`), 0644)
+		_, err := newChart(thread, repo, dir.Root())
+		Expect(err).To(MatchError(ContainSubstring("after must be a list of charts")))
+	})
+
+	It("applies and deletes independent subcharts in parallel", func() {
+		dir.WriteFile("Chart.star", []byte(`
+def init(self):
+  self.db = chart("db")
+  self.app = chart("app", after=[self.db])
+  self.cache = chart("cache")
+`), 0644)
+		c, err := newChart(thread, repo, dir.Root(), WithParallelism(3), WithSkipChart(true))
+		Expect(err).NotTo(HaveOccurred())
+		var mutex sync.Mutex
+		applied := []string{}
+		k := &k8s.FakeK8s{}
+		k.ForSubChartStub = func(namespace string, app string, version *semver.Version, children int) k8s.K8s {
+			return k
+		}
+		record := func(s k8s.ObjectStream, options *k8s.Options) error {
+			return s(func(obj *k8s.Object) error {
+				mutex.Lock()
+				defer mutex.Unlock()
+				applied = append(applied, obj.MetaData.Name)
+				return nil
+			})
+		}
+		k.ApplyStub = record
+		k.DeleteStub = record
+		Expect(c.Apply(thread, k)).To(Succeed())
+		Expect(applied).To(ConsistOf("db", "app", "cache"))
+		Expect(indexOf(applied, "db")).To(BeNumerically("<", indexOf(applied, "app")))
+
+		applied = []string{}
+		Expect(c.Delete(thread, k, &DeleteOptions{})).To(Succeed())
+		Expect(applied).To(ConsistOf("db", "app", "cache"))
+		Expect(indexOf(applied, "app")).To(BeNumerically("<", indexOf(applied, "db")))
+	})
+})
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,81 @@
+package kdo
+
+import (
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/starutils"
+)
+
+var _ = Describe("Hooks", func() {
+	var dir TestDir
+	thread := &starlark.Thread{Name: "main"}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		dir.WriteFile("Chart.star", []byte(`
+def init(self, fail = ""):
+	self.events = []
+	self.fail = fail
+	self.sub = chart("sub", events = self.events, fail = fail)
+def pre_apply(self, k8s):
+	self.events.append("pre_apply")
+def post_apply(self, k8s):
+	self.events.append("post_apply")
+def pre_delete(self, k8s):
+	self.events.append("pre_delete")
+def post_delete(self, k8s):
+	self.events.append("post_delete")
+def on_error(self, k8s, err):
+	self.events.append("on_error: " + err)
+`), 0644)
+		dir.MkdirAll("sub", 0755)
+		dir.WriteFile("sub/Chart.star", []byte(`
+def init(self, events, fail):
+	self.events = events
+	self.fail = fail
+def pre_apply(self, k8s):
+	self.events.append("sub pre_apply")
+def post_apply(self, k8s):
+	if self.fail == "apply":
+		fail("boom")
+	self.events.append("sub post_apply")
+def post_delete(self, k8s):
+	self.events.append("sub post_delete")
+def on_error(self, k8s, err):
+	self.events.append("sub on_error")
+`), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	events := func(c *chartImpl) []string {
+		return starutils.ToGoStringList(c.values["events"])
+	}
+
+	It("calls hooks around apply and delete", func() {
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), WithSkipChart(true))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Apply(thread, k8s.NewK8sInMemory("test"))).To(Succeed())
+		Expect(events(c)).To(Equal([]string{"pre_apply", "sub pre_apply", "sub post_apply", "post_apply"}))
+		c.values["events"].(*starlark.List).Clear()
+		Expect(c.Delete(thread, k8s.NewK8sInMemory("test"), &DeleteOptions{})).To(Succeed())
+		Expect(events(c)).To(Equal([]string{"pre_delete", "sub post_delete", "post_delete"}))
+	})
+
+	It("calls on_error", func() {
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), WithSkipChart(true), WithKwArgs([]starlark.Tuple{{starlark.String("fail"), starlark.String("apply")}}))
+		Expect(err).NotTo(HaveOccurred())
+		err = c.Apply(thread, k8s.NewK8sInMemory("test"))
+		Expect(err).To(MatchError(ContainSubstring("boom")))
+		Expect(events(c)).To(HaveLen(4))
+		Expect(events(c)[:3]).To(Equal([]string{"pre_apply", "sub pre_apply", "sub on_error"}))
+		Expect(events(c)[3]).To(HavePrefix("on_error: "))
+		Expect(events(c)[3]).To(ContainSubstring("boom"))
+	})
+})
@@ -0,0 +1,144 @@
+package kdo
+
+import (
+	"sort"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/starutils"
+)
+
+// ChartInfo describes the API of a chart, i.e. how it can be instantiated and configured
+type ChartInfo struct {
+	Name         string           `json:"name"`
+	Class        chartClass       `json:"class"`
+	Doc          string           `json:"doc,omitempty"`
+	Params       []ParamInfo      `json:"params,omitempty"`
+	Properties   []PropertyInfo   `json:"properties,omitempty"`
+	Methods      []string         `json:"methods,omitempty"`
+	Subcharts    []SubchartInfo   `json:"subcharts,omitempty"`
+	Jewels       []JewelInfo      `json:"jewels,omitempty"`
+	Dependencies []DependencyInfo `json:"dependencies,omitempty"`
+}
+
+// ParamInfo describes a parameter of init
+type ParamInfo struct {
+	Name     string      `json:"name"`
+	Default  interface{} `json:"default,omitempty"`
+	Required bool        `json:"required,omitempty"`
+}
+
+// PropertyInfo describes a property or struct_property
+type PropertyInfo struct {
+	Name        string         `json:"name"`
+	Type        string         `json:"type"`
+	Default     interface{}    `json:"default,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Choices     []interface{}  `json:"choices,omitempty"`
+	Pattern     string         `json:"pattern,omitempty"`
+	Min         interface{}    `json:"min,omitempty"`
+	Max         interface{}    `json:"max,omitempty"`
+	Properties  []PropertyInfo `json:"properties,omitempty"`
+}
+
+// SubchartInfo describes a subchart stored in an attribute of its parent
+type SubchartInfo struct {
+	Attribute string `json:"attribute"`
+	ChartInfo
+}
+
+// JewelInfo describes a credential, certificate or config value
+type JewelInfo struct {
+	Attribute string   `json:"attribute"`
+	Type      string   `json:"type"`
+	Secret    string   `json:"secret"`
+	Keys      []string `json:"keys"`
+}
+
+// DependencyInfo describes a dependency created with depends_on
+type DependencyInfo struct {
+	Attribute  string `json:"attribute"`
+	URL        string `json:"url"`
+	Constraint string `json:"constraint"`
+	Namespace  string `json:"namespace"`
+}
+
+// Info returns the description of the API of the chart
+func (c *chartImpl) Info() *ChartInfo {
+	info := &ChartInfo{Name: c.GetName(), Class: c.clazz}
+	if c.initFunc != nil {
+		info.Doc = c.initFunc.Doc()
+	}
+	for _, param := range c.initParams() {
+		p := ParamInfo{Name: param.name, Required: param.dflt == nil}
+		if param.dflt != nil {
+			p.Default = starutils.ToGo(param.dflt)
+		}
+		info.Params = append(info.Params, p)
+	}
+	for _, name := range c.definedMethods {
+		if name != "init" {
+			info.Methods = append(info.Methods, name)
+		}
+	}
+	sort.Strings(info.Methods)
+	for _, name := range c.sortedKeys() {
+		switch v := c.values[name].(type) {
+		case *chartImpl:
+			info.Subcharts = append(info.Subcharts, SubchartInfo{Attribute: name, ChartInfo: *v.Info()})
+		case *jewel:
+			keys := make([]string, 0)
+			for key := range v.backend.Keys() {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			info.Jewels = append(info.Jewels, JewelInfo{Attribute: name, Type: v.Type(), Secret: v.name, Keys: keys})
+		case *dependency:
+			info.Dependencies = append(info.Dependencies, DependencyInfo{Attribute: name, URL: v.url, Constraint: v.constraint.String(), Namespace: v.namespace})
+		case *property, *structProperty:
+			info.Properties = append(info.Properties, propertyInfo(name, v))
+		}
+	}
+	return info
+}
+
+func propertyInfo(name string, value starlark.Value) PropertyInfo {
+	switch p := value.(type) {
+	case *structProperty:
+		info := PropertyInfo{Name: name, Type: "struct"}
+		names := make([]string, 0, len(p.properties))
+		for n := range p.properties {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			info.Properties = append(info.Properties, propertyInfo(n, p.properties[n]))
+		}
+		return info
+	case *property:
+		info := PropertyInfo{Name: name, Type: p.typ, Required: p.required, Description: p.description}
+		if info.Type == "" {
+			info.Type = "any"
+		}
+		if p.dflt != starlark.None {
+			info.Default = starutils.ToGo(p.dflt)
+			if p.typ == "secret" {
+				info.Default = mask(p.dflt)
+			}
+		}
+		for _, choice := range p.choices {
+			info.Choices = append(info.Choices, starutils.ToGo(choice))
+		}
+		if p.pattern != nil {
+			info.Pattern = p.pattern.String()
+		}
+		if p.min != starlark.None {
+			info.Min = starutils.ToGo(p.min)
+		}
+		if p.max != starlark.None {
+			info.Max = starutils.ToGo(p.max)
+		}
+		return info
+	}
+	return PropertyInfo{Name: name, Type: value.Type()}
+}
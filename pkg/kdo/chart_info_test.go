@@ -0,0 +1,50 @@
+package kdo
+
+import (
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Info", func() {
+
+	It("describes the api of a chart", func() {
+		thread := &starlark.Thread{Name: "main"}
+		dir := NewTestDir()
+		defer dir.Remove()
+		dir.WriteFile("Chart.star", []byte(`
+def init(self, name, replicas = 1):
+	self.__class__.name = "example"
+	self.__class__.version = "1.0.0"
+	self.password = property(type = "secret", default = "geheim", description = "Admin password")
+	self.db = struct_property(port = property(type = "int", min = 1))
+	self.credential = user_credential("admin")
+	self.uaa = depends_on("uaa", ">= 1.0")
+	self.sub = chart("sub")
+def apply(self, k8s):
+	self.__apply(k8s)
+def rotate(self):
+	pass
+`), 0644)
+		dir.MkdirAll("sub", 0755)
+		dir.WriteFile("sub/values.yaml", []byte("timeout: 30s\n"), 0644)
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), WithArgs(starlark.Tuple{starlark.String("test")}))
+		Expect(err).NotTo(HaveOccurred())
+		info := c.Info()
+		Expect(info.Class.Name).To(Equal("example"))
+		Expect(info.Params).To(Equal([]ParamInfo{{Name: "name", Required: true}, {Name: "replicas", Default: int64(1)}}))
+		Expect(info.Methods).To(Equal([]string{"apply", "rotate"}))
+		Expect(info.Properties).To(Equal([]PropertyInfo{
+			{Name: "db", Type: "struct", Properties: []PropertyInfo{{Name: "port", Type: "int", Min: int64(1)}}},
+			{Name: "password", Type: "secret", Default: "******", Description: "Admin password"},
+		}))
+		Expect(info.Jewels).To(Equal([]JewelInfo{{Attribute: "credential", Type: "user_credential", Secret: "admin", Keys: []string{"password", "username"}}}))
+		Expect(info.Dependencies).To(HaveLen(1))
+		Expect(info.Dependencies[0].URL).To(Equal("uaa"))
+		Expect(info.Subcharts).To(HaveLen(1))
+		Expect(info.Subcharts[0].Attribute).To(Equal("sub"))
+		Expect(info.Subcharts[0].Properties).To(Equal([]PropertyInfo{{Name: "timeout", Type: "any", Default: "30s"}}))
+	})
+})
@@ -34,6 +34,7 @@ func (c *chartImpl) loadYaml(name string) error {
 	}
 	for k, v := range values {
 		c.values[k] = toProperty(v)
+		c.sources[k] = name
 	}
 	return nil
 }
@@ -88,8 +89,23 @@ func NewChartFunction(repo Repo, dir string, options ...ChartOption) func(thread
 		parser.Arg("suffix", func(value starlark.Value) {
 			co.suffix = value.(starlark.String).GoString()
 		})
+		var afterValue starlark.Value = starlark.None
+		parser.Arg("after", func(value starlark.Value) {
+			afterValue = value
+		})
 		WithKwArgs(parser.Parse())(co)
-		return repo.Get(thread, url, co.Merge())
+		after, err := parseAfter(afterValue)
+		if err != nil {
+			return starlark.None, err
+		}
+		c, err := repo.Get(thread, url, co.Merge())
+		if err != nil {
+			return starlark.None, err
+		}
+		if chart, ok := c.(*chartImpl); ok {
+			chart.after = after
+		}
+		return c, nil
 	}
 }
 
@@ -102,7 +118,12 @@ func (c *chartImpl) init(thread *starlark.Thread, hasChartYaml bool, co *ChartOp
 	c.methods["__delete"] = c.deleteLocalFunction()
 	c.methods["helm"] = c.helmTemplateFunction()
 	c.methods["ytt"] = c.yttTemplateFunction()
+	c.methods["kustomize"] = c.kustomizeTemplateFunction()
 	c.methods["load_yaml"] = c.loadYamlFunction()
+	c.methods["status"] = c.statusFunction()
+	c.methods["__status"] = c.statusFunction()
+	c.methods["notes"] = c.notesFunction()
+	c.methods["__notes"] = c.notesFunction()
 
 	file := c.path("Chart.star")
 	if _, err := os.Stat(file); err != nil {
@@ -112,8 +133,15 @@ func (c *chartImpl) init(thread *starlark.Thread, hasChartYaml bool, co *ChartOp
 		if !hasChartYaml {
 			return fmt.Errorf("Neither Chart.star nor Chart.yaml nor values.yaml exists in %s", c.dir)
 		}
+		if err := c.loadHelmDependencies(thread, co, nil); err != nil {
+			return err
+		}
 	} else {
-		usedBy := func() string { return fmt.Sprintf("%s-%s", c.namespace, c.genus) }
+		usedBy := func() string { return c.usedByReference(c.suffix) }
+		provider, err := c.encryption()
+		if err != nil {
+			return err
+		}
 		internal := starlark.StringDict{
 			"version":         starlark.String(version),
 			"kube_version":    starlark.String(kubeVersion),
@@ -122,7 +150,7 @@ func (c *chartImpl) init(thread *starlark.Thread, hasChartYaml bool, co *ChartOp
 			"user_credential": c.builtin("user_credential", makeUserCredential),
 			"config_value":    c.builtin("config_value", makeConfigValue),
 			"certificate":     c.builtin("certificate", makeCertificate),
-			"depends_on":      c.builtin("dependency", makeDependency(usedBy, c.repo, c.namespace)),
+			"depends_on":      c.builtin("dependency", makeDependency(usedBy, c.repo, c.namespace, provider)),
 			"property":        c.builtin("property", makeProperty),
 			"struct_property": c.builtin("struct_property", makeStructProperty),
 			"struct":          starlark.NewBuiltin("struct", starlarkstruct.Make),
@@ -137,14 +165,28 @@ func (c *chartImpl) init(thread *starlark.Thread, hasChartYaml bool, co *ChartOp
 			if k == "init" {
 				c.initFunc = v.(*starlark.Function)
 			}
+			if k == "migrations" {
+				if c.migrations, err = makeMigrations(v); err != nil {
+					return err
+				}
+			}
 			f, ok := v.(*starlark.Function)
 			if ok {
 				c.methods[k] = &chartMethod{Function: f, chart: c}
+				c.definedMethods = append(c.definedMethods, k)
 			}
 		}
 
+		if err := c.loadHelmDependencies(thread, co, globals); err != nil {
+			return err
+		}
+
 		if c.initFunc != nil {
-			_, err := starlark.Call(thread, c.initFunc, append([]starlark.Value{c}, co.args...), co.KwArgs(c.initFunc))
+			c.initKwargs = co.KwArgs(c.initFunc)
+			c.properties = co.properties
+			c.initializing = true
+			_, err := starlark.Call(thread, c.initFunc, append([]starlark.Value{c}, co.args...), c.placeholderArgs(co))
+			c.initializing = false
 			if err != nil {
 				return err
 			}
@@ -156,6 +198,25 @@ func (c *chartImpl) init(thread *starlark.Thread, hasChartYaml bool, co *ChartOp
 	return nil
 }
 
+// placeholderArgs returns the keyword arguments of init. With WithPlaceholderArgs, None is passed for required
+// parameters without value.
+func (c *chartImpl) placeholderArgs(co *ChartOptions) []starlark.Tuple {
+	if !co.placeholderArgs {
+		return c.initKwargs
+	}
+	kwargs := append([]starlark.Tuple{}, c.initKwargs...)
+	given := map[string]bool{}
+	for _, kwarg := range kwargs {
+		given[kwarg.Index(0).(starlark.String).GoString()] = true
+	}
+	for i, param := range c.initParams() {
+		if param.dflt == nil && i >= len(co.args) && !given[param.name] {
+			kwargs = append(kwargs, starlark.Tuple{starlark.String(param.name), starlark.None})
+		}
+	}
+	return kwargs
+}
+
 type chartMethod struct {
 	*starlark.Function
 	chart *chartImpl
@@ -171,7 +232,7 @@ func (s *chartMethod) CallInternal(thread *starlark.Thread, args starlark.Tuple,
 }
 
 func (c *chartImpl) wrapNamespace(callable starlark.Callable) starlark.Callable {
-	return c.builtin("wrap_namespace", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
+	return c.wrapper("wrap_namespace", callable, func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
 		if len(args) == 0 {
 			return nil, fmt.Errorf("Missing first argument k8s")
 		}
@@ -0,0 +1,73 @@
+package kdo
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/renderer"
+)
+
+// ChartNotes are the notes of a chart, which are shown to the user after installation
+type ChartNotes struct {
+	Name  string
+	Notes string
+}
+
+// Notes calls the notes method of the subcharts and the chart and returns all non empty notes
+func (c *chartImpl) Notes(thread *starlark.Thread, k k8s.K8s) ([]ChartNotes, error) {
+	inheritContext(thread, k)
+	result := []ChartNotes{}
+	return result, c.notes(thread, k8s.NewK8sValue(k), &result)
+}
+
+func (c *chartImpl) notes(thread *starlark.Thread, k k8s.K8sValue, result *[]ChartNotes) error {
+	err := c.eachSubChart(func(subChart *chartImpl) error {
+		return subChart.notes(thread, k, result)
+	})
+	if err != nil {
+		return err
+	}
+	args := starlark.Tuple{}
+	// notes defined in Chart.star can omit the k8s parameter
+	if method, ok := c.methods["notes"].(*chartMethod); !ok || method.NumParams() > 1 {
+		args = append(args, k)
+	}
+	value, err := starlark.Call(thread, c.methods["notes"], args, nil)
+	if err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case starlark.NoneType:
+	case starlark.String:
+		if value != "" {
+			*result = append(*result, ChartNotes{Name: c.GetName(), Notes: string(value)})
+		}
+	default:
+		return fmt.Errorf("invalid result of notes of chart %s: expected string, got %s", c.GetName(), value.Type())
+	}
+	return nil
+}
+
+func (c *chartImpl) notesFunction() starlark.Callable {
+	return c.builtin("notes", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
+		var v starlark.Value
+		if err := starlark.UnpackArgs("notes", args, kwargs, "k8s?", &v); err != nil {
+			return nil, err
+		}
+		filename := c.path("templates", renderer.NotesFile)
+		if _, err := os.Stat(filename); err != nil {
+			if os.IsNotExist(err) {
+				return starlark.None, nil
+			}
+			return nil, err
+		}
+		buffer := &bytes.Buffer{}
+		if err := c.helmFileRenderer(thread, k8sFromValue(v))(filename)(buffer); err != nil {
+			return nil, fmt.Errorf("error rendering %s: %w", filename, err)
+		}
+		return starlark.String(buffer.String()), nil
+	})
+}
@@ -0,0 +1,73 @@
+package kdo
+
+import (
+	"bytes"
+
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Notes", func() {
+	var dir TestDir
+	thread := &starlark.Thread{Name: "main"}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		dir.MkdirAll("templates", 0755)
+		dir.WriteFile("Chart.yaml", []byte("name: uaa\nversion: 1.0.0\n"), 0644)
+		dir.WriteFile("values.yaml", []byte("host: uaa.example.com\n"), 0644)
+		dir.WriteFile("templates/cm.yaml", []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: uaa\n"), 0644)
+		dir.WriteFile("templates/NOTES.txt", []byte("{{ .Release.Name }} is available at https://{{ .Values.host }}\n"), 0644)
+		dir.MkdirAll("sub", 0755)
+		dir.WriteFile("sub/Chart.star", []byte(`
+def init(self):
+	self.user = "admin"
+def notes(self):
+	return "login as " + self.user
+`), 0644)
+		dir.MkdirAll("empty/templates", 0755)
+		dir.WriteFile("empty/Chart.yaml", []byte("name: empty\nversion: 1.0.0\n"), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	It("renders NOTES.txt and notes of subcharts", func() {
+		dir.WriteFile("Chart.star", []byte(`
+def init(self):
+	self.sub = chart("sub")
+	self.empty = chart("empty")
+`), 0644)
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), WithNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		k := k8s.NewK8sInMemory("test")
+		Expect(c.Apply(thread, k)).To(Succeed())
+		notes, err := c.Notes(thread, k)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notes).To(Equal([]ChartNotes{
+			{Name: "sub", Notes: "login as admin"},
+			{Name: "uaa", Notes: "uaa is available at https://uaa.example.com\n"},
+		}))
+
+		buffer := &bytes.Buffer{}
+		Expect(c.Template(thread, k)(buffer)).To(Succeed())
+		Expect(buffer.String()).NotTo(ContainSubstring("available"))
+	})
+
+	It("can be overridden", func() {
+		dir.WriteFile("Chart.star", []byte(`
+def notes(self, k8s):
+	return self.__notes(k8s).upper()
+`), 0644)
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root())
+		Expect(err).NotTo(HaveOccurred())
+		notes, err := c.Notes(thread, k8s.NewK8sInMemory("test"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notes).To(Equal([]ChartNotes{{Name: "uaa", Notes: "UAA IS AVAILABLE AT HTTPS://UAA.EXAMPLE.COM\n"}}))
+	})
+})
@@ -6,16 +6,19 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
 	"github.com/sap/kubernetes-deployment-orchestrator/pkg/starutils"
 	"github.com/spf13/pflag"
 )
 
 // Properties -
 type Properties struct {
-	dict *starlark.Dict
+	dict    *starlark.Dict
+	sources map[string]string
 }
 
 func (p Properties) String() string {
@@ -63,19 +66,28 @@ func (p *Properties) delete(key string) starlark.Value {
 	return v
 }
 
-func (p *Properties) set(key string, value starlark.Value) {
+func (p *Properties) set(key string, value starlark.Value, source string) {
 	if p.dict == nil {
 		p.dict = starlark.NewDict(0)
 	}
+	if p.sources == nil {
+		p.sources = make(map[string]string)
+	}
 	p.dict.SetKey(starlark.String(key), value)
+	p.sources[key] = source
 }
 
-func (p *Properties) setWithMap(values map[string]interface{}) {
+func (p *Properties) setWithMap(values map[string]interface{}, source string) {
 	for k, v := range values {
-		p.set(k, starutils.ToStarlark(v))
+		p.set(k, starutils.ToStarlark(v), source)
 	}
 }
 
+// source returns where the value of key was set, e.g. --set replicas
+func (p *Properties) source(key string) string {
+	return p.sources[key]
+}
+
 // GetValue -
 func (p *Properties) GetValue() starlark.Value {
 	if p.dict == nil {
@@ -87,7 +99,7 @@ func (p *Properties) GetValue() starlark.Value {
 // Set -
 func (p *Properties) Set(val string) error {
 	return parseSet(val, func(key string, value string) error {
-		p.set(key, starlark.String(value))
+		p.set(key, starlark.String(value), "--set "+key)
 		return nil
 	})
 }
@@ -113,7 +125,7 @@ func (p *propertiesYamlVar) Set(val string) error {
 		if err != nil {
 			return err
 		}
-		p.properties.set(key, starutils.ToStarlark(v))
+		p.properties.set(key, starutils.ToStarlark(v), "--set-yaml "+key)
 		return nil
 	})
 }
@@ -139,7 +151,7 @@ func (p *proeprtiesFileVar) Set(val string) error {
 		if err != nil {
 			return err
 		}
-		p.properties.set(key, starlark.String(string(data)))
+		p.properties.set(key, starlark.String(string(data)), "--set-file "+key)
 		return nil
 	})
 }
@@ -160,7 +172,7 @@ func (p propertiesEnvVar) String() string {
 // Set -
 func (p *propertiesEnvVar) Set(val string) error {
 	return parseSet(val, func(key string, value string) error {
-		p.properties.set(key, starlark.String(os.Getenv(value)))
+		p.properties.set(key, starlark.String(os.Getenv(value)), "--set-env "+key)
 		return nil
 	})
 }
@@ -185,7 +197,7 @@ func (p *propertiesFile) Set(val string) error {
 	if err != nil {
 		return err
 	}
-	p.properties.setWithMap(values)
+	p.properties.setWithMap(values, "--values "+val)
 	return nil
 }
 
@@ -197,9 +209,16 @@ func (p propertiesFile) Type() string {
 type GenusAndVersion struct {
 	genus   string
 	version *semver.Version
+	url     string
 }
 
 func NewGenusAndVersion(url string) *GenusAndVersion {
+	result := newGenusAndVersion(url)
+	result.url = url
+	return result
+}
+
+func newGenusAndVersion(url string) *GenusAndVersion {
 	var match []string
 	if match = githubRelease.FindStringSubmatch(url); match != nil {
 		return extractGenusAndVersion(match[1], match[2])
@@ -216,18 +235,34 @@ func NewGenusAndVersion(url string) *GenusAndVersion {
 	if match = catalogURL.FindStringSubmatch(url); match != nil {
 		return extractGenusAndVersion(match[1], "")
 	}
-	return extractGenusAndVersion(path.Base(url), "")
+	// archives in the charts directory of helm charts, e.g. charts/mysql-1.6.9.tgz
+	if match = helmArchive.FindStringSubmatch(url); match != nil && !urlPattern.MatchString(url) {
+		return extractGenusAndVersion(match[1], match[2])
+	}
+	return extractGenusAndVersion(path.Base(strings.SplitN(url, "?", 2)[0]), "")
 }
 
 // ChartOptions -
 type ChartOptions struct {
 	GenusAndVersion
-	namespace  string
-	suffix     string
-	args       starlark.Tuple
-	properties Properties
-	skipChart  bool
-	readOnly   bool
+	namespace        string
+	suffix           string
+	args             starlark.Tuple
+	properties       Properties
+	skipChart        bool
+	readOnly         bool
+	parallelism      int
+	allowDowngrade   bool
+	reuseFrom        k8s.K8sReader
+	reuseQuiet       bool
+	reuseAll         bool
+	encryptionKey    string
+	keyProvider      KeyProvider
+	allowUnencrypted bool
+	placeholderArgs  bool
+	postRenderExec   string
+	images           imageConfig
+	configImages     imageConfig
 }
 
 // ChartOption -
@@ -241,6 +276,9 @@ func (s *GenusAndVersion) AsOptions() []ChartOption {
 	if s.version != nil {
 		result = append(result, WithVersion(s.version))
 	}
+	if s.url != "" {
+		result = append(result, withURL(s.url))
+	}
 	return result
 }
 
@@ -259,6 +297,11 @@ func WithVersion(value *semver.Version) ChartOption {
 	return func(options *ChartOptions) { options.version = value }
 }
 
+// withURL records the url the chart was loaded from
+func withURL(url string) ChartOption {
+	return func(options *ChartOptions) { options.url = url }
+}
+
 // WithSuffix -
 func WithSuffix(suffix string) ChartOption {
 	return func(options *ChartOptions) { options.suffix = suffix }
@@ -281,7 +324,7 @@ func WithKwArgs(kwargs []starlark.Tuple) ChartOption {
 			if arg.Len() == 2 {
 				key, keyOK := arg.Index(0).(starlark.String)
 				if keyOK {
-					options.properties.set(key.GoString(), arg.Index(1))
+					options.properties.set(key.GoString(), arg.Index(1), "chart() arguments")
 				}
 			}
 		}
@@ -290,7 +333,7 @@ func WithKwArgs(kwargs []starlark.Tuple) ChartOption {
 
 // WithValues -
 func WithValues(values map[string]interface{}) ChartOption {
-	return func(options *ChartOptions) { options.properties.setWithMap(values) }
+	return func(options *ChartOptions) { options.properties.setWithMap(values, "values") }
 }
 
 // WithSkipChart -
@@ -303,6 +346,82 @@ func WithReadOnly(value bool) ChartOption {
 	return func(options *ChartOptions) { options.readOnly = value }
 }
 
+// WithPostRenderer - executable which modifies the rendered objects (like helm --post-renderer). It runs once with the
+// objects of the chart and all its subcharts.
+func WithPostRenderer(executable string) ChartOption {
+	return func(options *ChartOptions) { options.postRenderExec = executable }
+}
+
+// WithImageMapping rewrites the images of the rendered workloads, which start with from, to start with to
+func WithImageMapping(from string, to string) ChartOption {
+	return func(options *ChartOptions) {
+		options.images.mappings = append(options.images.mappings, ImageMapping{From: from, To: to})
+	}
+}
+
+// WithImageLock pins the images of the rendered workloads to the digests of the lock file
+func WithImageLock(filename string) ChartOption {
+	return func(options *ChartOptions) { options.images.lock = filename }
+}
+
+// withConfigImages sets the image mappings of the kdo configuration, which apply after those given explicitly
+func withConfigImages(images imageConfig) ChartOption {
+	return func(options *ChartOptions) { options.configImages = images }
+}
+
+// WithAllowDowngrade -
+func WithAllowDowngrade(value bool) ChartOption {
+	return func(options *ChartOptions) { options.allowDowngrade = value }
+}
+
+// WithReuseValues reuses the values persisted by the previous apply of the chart, which aren't given explicitly
+func WithReuseValues(k k8s.K8sReader) ChartOption {
+	return func(options *ChartOptions) { options.reuseFrom = k }
+}
+
+// withPersistedValues restores the installed chart from all persisted values, not only the explicit ones, without
+// reporting them
+func withPersistedValues(k k8s.K8sReader) ChartOption {
+	return func(options *ChartOptions) {
+		options.reuseFrom = k
+		options.reuseQuiet = true
+		options.reuseAll = true
+	}
+}
+
+// WithEncryption encrypts the values persisted in the secret of the chart with keys wrapped by provider
+func WithEncryption(provider KeyProvider) ChartOption {
+	return func(options *ChartOptions) { options.keyProvider = provider }
+}
+
+// WithPlaceholderArgs passes None for required parameters of init without value, which allows to describe a chart
+// without values
+func WithPlaceholderArgs(value bool) ChartOption {
+	return func(options *ChartOptions) { options.placeholderArgs = value }
+}
+
+// WithAllowUnencrypted allows to replace encrypted values in the secret of the chart with unencrypted values
+func WithAllowUnencrypted(value bool) ChartOption {
+	return func(options *ChartOptions) { options.allowUnencrypted = value }
+}
+
+// encryption returns the key provider given with WithEncryption or --encryption-key, nil if values aren't encrypted
+func (v *ChartOptions) encryption() (KeyProvider, error) {
+	if v.keyProvider == nil && v.encryptionKey != "" {
+		provider, err := NewKeyProvider(v.encryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		v.keyProvider = provider
+	}
+	return v.keyProvider, nil
+}
+
+// WithParallelism -
+func WithParallelism(value int) ChartOption {
+	return func(options *ChartOptions) { options.parallelism = value }
+}
+
 // AddFlags -
 func (v *ChartOptions) AddFlags(flagsSet *pflag.FlagSet) {
 	defaultNamespace := os.Getenv("KDO_NAMESPACE")
@@ -318,9 +437,30 @@ func (v *ChartOptions) AddFlags(flagsSet *pflag.FlagSet) {
 	flagsSet.VarP(&propertiesFile{properties: &v.properties}, "values", "f", "Load additional values from a file")
 }
 
+// AddRenderFlags - flags which modify the rendered objects, for commands which apply or print them
+func (v *ChartOptions) AddRenderFlags(flagsSet *pflag.FlagSet) {
+	flagsSet.Var(&imageMappingsVar{images: &v.images}, "image-mapping", "Rewrite the images of the rendered workloads with this prefix to another registry (from=to)")
+	flagsSet.StringVar(&v.images.lock, "image-lock", "", "YAML file with digests the images of the rendered workloads are pinned to")
+	flagsSet.StringVar(&v.postRenderExec, "post-renderer", "", "Executable which gets the objects rendered by the chart and its subcharts on stdin and writes the modified objects to stdout")
+}
+
+// AddParallelismFlag - flag for commands which apply or delete subcharts
+func (v *ChartOptions) AddParallelismFlag(flagsSet *pflag.FlagSet) {
+	flagsSet.IntVar(&v.parallelism, "parallelism", 1, "Maximum number of independent subcharts of a chart which are applied or deleted concurrently")
+}
+
+// AddApplyFlags - flags of the apply command in addition to AddFlags
+func (v *ChartOptions) AddApplyFlags(flagsSet *pflag.FlagSet) {
+	v.AddRenderFlags(flagsSet)
+	v.AddParallelismFlag(flagsSet)
+	flagsSet.StringVar(&v.encryptionKey, "encryption-key", os.Getenv("KDO_ENCRYPTION_KEY"), "Encrypt the values stored in the cluster with this key (file://<path>, age://<path> or kms://<path>)")
+	flagsSet.BoolVar(&v.allowUnencrypted, "allow-unencrypted", false, "Store the values unencrypted even if they were encrypted by the previous apply")
+	flagsSet.BoolVar(&v.allowDowngrade, "allow-downgrade", false, "Allow to apply a chart with a lower version than the installed one")
+}
+
 func (v *ChartOptions) KwArgs(f *starlark.Function) []starlark.Tuple {
 	result := []starlark.Tuple{}
-	for i := 1; i < f.NumParams(); i++ {
+	for i := 1; i < numNamedParams(f); i++ {
 		arg, _ := f.Param(i)
 		value := v.properties.delete(arg)
 		if value != starlark.None {
@@ -81,6 +81,16 @@ var _ = Describe("Chart Options", func() {
 			Expect(flagsSet.FlagUsages()).To(ContainSubstring(` --set properties             Set values (key=val)`))
 			Expect(flagsSet.FlagUsages()).To(ContainSubstring(`-n, --namespace string           namespace for installation (default "default")`))
 			Expect(flagsSet.FlagUsages()).To(ContainSubstring(`-s, --suffix string              Suffix which is used to build the chart name`))
+			Expect(flagsSet.FlagUsages()).NotTo(ContainSubstring(`--allow-downgrade`))
+			Expect(flagsSet.FlagUsages()).NotTo(ContainSubstring(`--image-mapping`))
+		})
+		It("apply has additional args", func() {
+			args := ChartOptions{}
+			flagsSet := pflag.FlagSet{}
+			args.AddApplyFlags(&flagsSet)
+			for _, name := range []string{"encryption-key", "allow-unencrypted", "allow-downgrade", "parallelism", "image-mapping", "image-lock", "post-renderer"} {
+				Expect(flagsSet.Lookup(name)).NotTo(BeNil(), name)
+			}
 		})
 	})
 })
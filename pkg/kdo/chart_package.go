@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/base64"
+	"encoding/json"
 	"io"
 	"log"
 	"os"
@@ -39,7 +40,7 @@ func (c *chartImpl) packageHelm(writer io.Writer) error {
 	}
 	args := make([]string, 0)
 	if c.initFunc != nil {
-		for i := 1; i < c.initFunc.NumParams(); i++ {
+		for i := 1; i < numNamedParams(c.initFunc); i++ {
 			arg, _ := c.initFunc.Param(i)
 			args = append(args, arg)
 		}
@@ -76,7 +77,13 @@ func (c *chartImpl) packageHelm(writer io.Writer) error {
 	}); err != nil {
 		return err
 	}
-
+	if err := writeFile(tw, path.Join(c.clazz.Name, "values.schema.json"), func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(c.Schema().Nullable())
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
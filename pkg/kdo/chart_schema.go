@@ -0,0 +1,314 @@
+package kdo
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/k14s/starlark-go/syntax"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/starutils"
+)
+
+// SchemaVersion is the JSON schema dialect of generated schemas
+const SchemaVersion = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON schema describing the values of a chart
+type Schema struct {
+	Schema                string             `json:"$schema,omitempty"`
+	Title                 string             `json:"title,omitempty"`
+	Description           string             `json:"description,omitempty"`
+	Type                  interface{}        `json:"type,omitempty"`
+	Format                string             `json:"format,omitempty"`
+	WriteOnly             bool               `json:"writeOnly,omitempty"`
+	Enum                  []interface{}      `json:"enum,omitempty"`
+	Pattern               string             `json:"pattern,omitempty"`
+	Minimum               *float64           `json:"minimum,omitempty"`
+	Maximum               *float64           `json:"maximum,omitempty"`
+	MinLength             *int64             `json:"minLength,omitempty"`
+	MaxLength             *int64             `json:"maxLength,omitempty"`
+	MinItems              *int64             `json:"minItems,omitempty"`
+	MaxItems              *int64             `json:"maxItems,omitempty"`
+	MinProperties         *int64             `json:"minProperties,omitempty"`
+	MaxProperties         *int64             `json:"maxProperties,omitempty"`
+	Default               interface{}        `json:"default,omitempty"`
+	Properties            map[string]*Schema `json:"properties,omitempty"`
+	Required              []string           `json:"required,omitempty"`
+	AdditionalProperties  *bool              `json:"additionalProperties,omitempty"`
+	PreserveUnknownFields *bool              `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+}
+
+var schemaTypes = map[string]string{
+	"string": "string",
+	"secret": "string",
+	"int":    "integer",
+	"float":  "number",
+	"bool":   "boolean",
+	"list":   "array",
+	"dict":   "object",
+}
+
+// Schema returns the JSON schema of the values of the chart. It contains the parameters of init,
+// all properties and the properties of subcharts.
+func (c *chartImpl) Schema() *Schema {
+	schema := c.schema()
+	schema.Schema = SchemaVersion
+	for _, param := range c.initParams() {
+		s := &Schema{}
+		if param.dflt == nil {
+			schema.Required = append(schema.Required, param.name)
+		} else if param.dflt != starlark.None {
+			s.Default = starutils.ToGo(param.dflt)
+		}
+		schema.Properties[param.name] = s
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+func (c *chartImpl) schema() *Schema {
+	schema := &Schema{Title: c.clazz.Name, Description: c.clazz.Description, Type: "object", Properties: map[string]*Schema{}}
+	for name, value := range c.values {
+		s := valueSchema(value)
+		if s == nil {
+			continue
+		}
+		if p, ok := value.(*property); ok && p.required {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = s
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// valueSchema returns the schema of a value of a chart or nil if the value isn't a property
+func valueSchema(value starlark.Value) *Schema {
+	switch v := value.(type) {
+	case *chartImpl:
+		return v.schema()
+	case *structProperty:
+		return v.schema()
+	case *property:
+		return v.schema()
+	}
+	return nil
+}
+
+func (s *structProperty) schema() *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for name, p := range s.properties {
+		if ps := valueSchema(p); ps != nil {
+			schema.Properties[name] = ps
+		}
+		if p, ok := p.(*property); ok && p.required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	if !s.additionalProperties {
+		schema.AdditionalProperties = new(bool)
+	}
+	return schema
+}
+
+func (s *property) schema() *Schema {
+	typ := s.typ
+	if typ == "enum" {
+		typ = choicesType(s.choices)
+	}
+	schema := &Schema{Description: s.description}
+	if t, ok := schemaTypes[typ]; ok {
+		schema.Type = t
+	}
+	if typ == "secret" {
+		schema.Format = "password"
+		schema.WriteOnly = true
+	}
+	if s.dflt != starlark.None {
+		schema.Default = starutils.ToGo(s.dflt)
+	}
+	for _, choice := range s.choices {
+		schema.Enum = append(schema.Enum, starutils.ToGo(choice))
+	}
+	if s.pattern != nil {
+		schema.Pattern = s.pattern.String()
+	}
+	min, hasMin := starlark.AsFloat(s.min)
+	max, hasMax := starlark.AsFloat(s.max)
+	bound := func(f float64, ok bool) *int64 {
+		if !ok {
+			return nil
+		}
+		i := int64(f)
+		return &i
+	}
+	switch typ {
+	case "string", "secret":
+		schema.MinLength, schema.MaxLength = bound(min, hasMin), bound(max, hasMax)
+	case "list":
+		schema.MinItems, schema.MaxItems = bound(min, hasMin), bound(max, hasMax)
+	case "dict":
+		schema.MinProperties, schema.MaxProperties = bound(min, hasMin), bound(max, hasMax)
+	default:
+		if hasMin {
+			schema.Minimum = &min
+		}
+		if hasMax {
+			schema.Maximum = &max
+		}
+	}
+	return schema
+}
+
+// choicesType returns the type of the choices of an enum, if all have the same type
+func choicesType(choices []starlark.Value) string {
+	typ := ""
+	for i, choice := range choices {
+		t := inferType(choice)
+		if i != 0 && t != typ {
+			return ""
+		}
+		typ = t
+	}
+	return typ
+}
+
+// Nullable allows null for all top level properties. Helm passes unset values of the generated values.yaml as null.
+func (s *Schema) Nullable() *Schema {
+	result := *s
+	result.Required = nil
+	result.Properties = make(map[string]*Schema, len(s.Properties))
+	for name, p := range s.Properties {
+		property := *p
+		if property.Type != nil {
+			property.Type = []interface{}{property.Type, "null"}
+		}
+		if property.Enum != nil {
+			property.Enum = append(append([]interface{}{}, property.Enum...), nil)
+		}
+		result.Properties[name] = &property
+	}
+	return &result
+}
+
+// Structural converts the schema into a structural schema as required by the openAPIV3Schema of custom resource
+// definitions. Defaults are removed, because apiextensions.k8s.io/v1beta1 doesn't support them.
+func (s *Schema) Structural() *Schema {
+	result := *s
+	result.Schema = ""
+	result.Title = ""
+	result.WriteOnly = false
+	result.Default = nil
+	result.AdditionalProperties = nil
+	if s.Type == "object" && s.AdditionalProperties == nil {
+		preserve := true
+		result.PreserveUnknownFields = &preserve
+	}
+	if s.Properties != nil {
+		result.Properties = make(map[string]*Schema, len(s.Properties))
+		for name, p := range s.Properties {
+			result.Properties[name] = p.Structural()
+		}
+	}
+	return &result
+}
+
+type initParam struct {
+	name string
+	// dflt is the default value, nil if the parameter has no default
+	dflt starlark.Value
+}
+
+// initParams returns the parameters of init except self. Defaults, which aren't literals, are reported as None.
+func (c *chartImpl) initParams() []initParam {
+	if c.initFunc == nil {
+		return nil
+	}
+	defaults := map[string]syntax.Expr{}
+	if f, err := syntax.Parse(c.path("Chart.star"), nil, 0); err == nil {
+		for _, stmt := range f.Stmts {
+			def, ok := stmt.(*syntax.DefStmt)
+			if !ok || def.Name.Name != "init" {
+				continue
+			}
+			for _, param := range def.Params {
+				if binary, ok := param.(*syntax.BinaryExpr); ok && binary.Op == syntax.EQ {
+					if ident, ok := binary.X.(*syntax.Ident); ok {
+						defaults[ident.Name] = binary.Y
+					}
+				}
+			}
+		}
+	}
+	params := []initParam{}
+	for i := 1; i < numNamedParams(c.initFunc); i++ {
+		name, _ := c.initFunc.Param(i)
+		param := initParam{name: name}
+		if expr, ok := defaults[name]; ok {
+			param.dflt = literalValue(expr)
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// numNamedParams returns the number of parameters of f without *args and **kwargs, which come last
+func numNamedParams(f *starlark.Function) int {
+	result := f.NumParams()
+	if f.HasVarargs() {
+		result--
+	}
+	if f.HasKwargs() {
+		result--
+	}
+	return result
+}
+
+// literalValue returns the value of simple literals or None
+func literalValue(expr syntax.Expr) starlark.Value {
+	switch e := expr.(type) {
+	case *syntax.Literal:
+		switch v := e.Value.(type) {
+		case string:
+			return starlark.String(v)
+		case int64:
+			return starlark.MakeInt64(v)
+		case float64:
+			return starlark.Float(v)
+		}
+		if i, err := strconv.ParseInt(e.Raw, 0, 64); err == nil {
+			return starlark.MakeInt64(i)
+		}
+	case *syntax.Ident:
+		switch e.Name {
+		case "True":
+			return starlark.True
+		case "False":
+			return starlark.False
+		}
+	case *syntax.UnaryExpr:
+		if e.Op == syntax.MINUS {
+			switch v := literalValue(e.X).(type) {
+			case starlark.Int:
+				return starlark.MakeInt(0).Sub(v)
+			case starlark.Float:
+				return -v
+			}
+		}
+	case *syntax.ListExpr:
+		list := make([]starlark.Value, 0, len(e.List))
+		for _, x := range e.List {
+			list = append(list, literalValue(x))
+		}
+		return starlark.NewList(list)
+	case *syntax.DictExpr:
+		dict := starlark.NewDict(len(e.List))
+		for _, x := range e.List {
+			if entry, ok := x.(*syntax.DictEntry); ok {
+				dict.SetKey(literalValue(entry.Key), literalValue(entry.Value))
+			}
+		}
+		return dict
+	}
+	return starlark.None
+}
@@ -0,0 +1,114 @@
+package kdo
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Schema", func() {
+	var dir TestDir
+	var c *chartImpl
+	thread := &starlark.Thread{Name: "main"}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		dir.WriteFile("Chart.star", []byte(`
+def init(self, name, size = 3, labels = {"a": "b"}):
+	self.__class__.name = "example"
+	self.replicas = property(type = "int", default = 1, min = 1, description = "Number of replicas")
+	self.mode = property(choices = ["fast", "safe"], default = "safe")
+	self.password = property(type = "secret", required = True, min = 8)
+	self.db = struct_property(port = property(type = "int", required = True))
+	self.sub = chart("sub")
+	self.plain = "value"
+`), 0644)
+		dir.MkdirAll("sub", 0755)
+		dir.WriteFile("sub/values.yaml", []byte("timeout: 30s\n"), 0644)
+		repo, _ := NewRepo()
+		var err error
+		c, err = newChart(thread, repo, dir.Root(), WithArgs(starlark.Tuple{starlark.String("test")}))
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	toMap := func(s *Schema) map[string]interface{} {
+		data, err := json.Marshal(s)
+		Expect(err).NotTo(HaveOccurred())
+		var result map[string]interface{}
+		Expect(json.Unmarshal(data, &result)).To(Succeed())
+		return result
+	}
+
+	It("describes properties, init parameters and subcharts", func() {
+		s := toMap(c.Schema())
+		Expect(s["$schema"]).To(Equal(SchemaVersion))
+		Expect(s["title"]).To(Equal("example"))
+		Expect(s["required"]).To(Equal([]interface{}{"name", "password"}))
+		properties := s["properties"].(map[string]interface{})
+		Expect(properties).NotTo(HaveKey("plain"))
+		Expect(properties["name"]).To(Equal(map[string]interface{}{}))
+		Expect(properties["size"]).To(Equal(map[string]interface{}{"default": 3.0}))
+		Expect(properties["labels"]).To(Equal(map[string]interface{}{"default": map[string]interface{}{"a": "b"}}))
+		Expect(properties["replicas"]).To(Equal(map[string]interface{}{"type": "integer", "default": 1.0, "minimum": 1.0, "description": "Number of replicas"}))
+		Expect(properties["mode"]).To(Equal(map[string]interface{}{"type": "string", "default": "safe", "enum": []interface{}{"fast", "safe"}}))
+		Expect(properties["password"]).To(Equal(map[string]interface{}{"type": "string", "format": "password", "writeOnly": true, "minLength": 8.0}))
+		Expect(properties["db"]).To(Equal(map[string]interface{}{"type": "object", "additionalProperties": false, "required": []interface{}{"port"},
+			"properties": map[string]interface{}{"port": map[string]interface{}{"type": "integer"}}}))
+		Expect(properties["sub"]).To(HaveKeyWithValue("properties", map[string]interface{}{"timeout": map[string]interface{}{"default": "30s"}}))
+	})
+
+	It("ignores *args and **kwargs of init", func() {
+		dir.WriteFile("Chart.star", []byte("def init(self, a, *args, **kw):\n\tpass\n"), 0644)
+		repo, _ := NewRepo()
+		_, err := newChart(thread, repo, dir.Root())
+		Expect(err).To(MatchError(ContainSubstring("missing 1 argument (a)")))
+		c, err := newChart(thread, repo, dir.Root(), WithPlaceholderArgs(true))
+		Expect(err).NotTo(HaveOccurred())
+		s := toMap(c.Schema())
+		Expect(s["required"]).To(Equal([]interface{}{"a"}))
+		Expect(s["properties"]).To(Equal(map[string]interface{}{"a": map[string]interface{}{}}))
+		Expect(c.Info().Params).To(Equal([]ParamInfo{{Name: "a", Required: true}}))
+	})
+
+	It("creates a structural schema", func() {
+		s := toMap(c.Schema().Structural())
+		Expect(s).NotTo(HaveKey("$schema"))
+		properties := s["properties"].(map[string]interface{})
+		Expect(properties["replicas"]).NotTo(HaveKey("default"))
+		Expect(properties["db"]).NotTo(HaveKey("additionalProperties"))
+		Expect(properties["sub"]).To(HaveKeyWithValue("x-kubernetes-preserve-unknown-fields", true))
+	})
+
+	It("embeds the schema in helm charts", func() {
+		buffer := &bytes.Buffer{}
+		Expect(c.Package(buffer, true)).To(Succeed())
+		gz, err := gzip.NewReader(buffer)
+		Expect(err).NotTo(HaveOccurred())
+		tr := tar.NewReader(gz)
+		for {
+			hdr, err := tr.Next()
+			Expect(err).NotTo(HaveOccurred())
+			if hdr.Name != "example/values.schema.json" {
+				continue
+			}
+			data, err := ioutil.ReadAll(tr)
+			Expect(err).NotTo(HaveOccurred())
+			var s map[string]interface{}
+			Expect(json.Unmarshal(data, &s)).To(Succeed())
+			Expect(s).NotTo(HaveKey("required"))
+			Expect(s["properties"]).To(HaveKeyWithValue("replicas", HaveKeyWithValue("type", []interface{}{"integer", "null"})))
+			Expect(s["properties"]).To(HaveKeyWithValue("mode", HaveKeyWithValue("enum", []interface{}{"fast", "safe", nil})))
+			break
+		}
+	})
+})
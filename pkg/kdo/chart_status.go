@@ -0,0 +1,289 @@
+package kdo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/starutils"
+)
+
+const (
+	// HealthReady is reported if all objects of a chart are ready
+	HealthReady = "ready"
+	// HealthProgressing is reported if some objects of a chart aren't ready yet
+	HealthProgressing = "progressing"
+	// HealthMissing is reported if objects of a chart don't exist
+	HealthMissing = "missing"
+	// HealthUnknown is reported if the status method of a chart doesn't return a health
+	HealthUnknown = "unknown"
+)
+
+// ChartStatus describes the state of an installed chart and its subcharts
+type ChartStatus struct {
+	Name      string        `json:"name"`
+	Health    string        `json:"health"`
+	Endpoints []string      `json:"endpoints,omitempty"`
+	Messages  []string      `json:"messages,omitempty"`
+	Subcharts []ChartStatus `json:"subcharts,omitempty"`
+}
+
+// String returns a one line summary of the status without subcharts, e.g. "uaa: ready, 3/3 pods, endpoint https://uaa"
+func (s *ChartStatus) String() string {
+	parts := append([]string{s.Health}, s.Messages...)
+	for _, endpoint := range s.Endpoints {
+		parts = append(parts, "endpoint "+endpoint)
+	}
+	return fmt.Sprintf("%s: %s", s.Name, strings.Join(parts, ", "))
+}
+
+// Ready returns true if the chart and all its subcharts are ready
+func (s *ChartStatus) Ready() bool {
+	if s.Health != HealthReady {
+		return false
+	}
+	for _, sub := range s.Subcharts {
+		if !sub.Ready() {
+			return false
+		}
+	}
+	return true
+}
+
+// Status calls the status method of the chart and its subcharts
+func (c *chartImpl) Status(thread *starlark.Thread, k k8s.K8s) (*ChartStatus, error) {
+	inheritContext(thread, k)
+	var status *ChartStatus
+	err := c.withTreeRender(func() (err error) {
+		status, err = c.status(thread, k8s.NewK8sValue(k))
+		return err
+	})
+	return status, err
+}
+
+func (c *chartImpl) status(thread *starlark.Thread, k k8s.K8sValue) (*ChartStatus, error) {
+	value, err := starlark.Call(thread, c.methods["status"], starlark.Tuple{k}, nil)
+	if err != nil {
+		return nil, err
+	}
+	status, err := toChartStatus(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result of status of chart %s: %w", c.GetName(), err)
+	}
+	status.Name = c.GetName()
+	err = c.eachSubChart(func(subChart *chartImpl) error {
+		sub, err := subChart.status(thread, k)
+		if err != nil {
+			return err
+		}
+		status.Subcharts = append(status.Subcharts, *sub)
+		return nil
+	})
+	return status, err
+}
+
+func toChartStatus(value starlark.Value) (*ChartStatus, error) {
+	status := &ChartStatus{Health: HealthUnknown}
+	if value == starlark.None {
+		return status, nil
+	}
+	dict, ok := value.(starlark.IterableMapping)
+	if !ok {
+		return nil, fmt.Errorf("expected dict, got %s", value.Type())
+	}
+	for key, v := range starutils.ToGoMap(dict) {
+		var err error
+		switch key {
+		case "health":
+			status.Health = fmt.Sprint(v)
+		case "endpoints":
+			status.Endpoints, err = stringList(key, v)
+		case "messages":
+			status.Messages, err = stringList(key, v)
+		default:
+			err = fmt.Errorf("unknown key %s, allowed are health, endpoints and messages", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+func stringList(key string, v interface{}) ([]string, error) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list", key)
+	}
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		result = append(result, fmt.Sprint(item))
+	}
+	return result, nil
+}
+
+func (c *chartImpl) statusFunction() starlark.Callable {
+	return c.builtin("status", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
+		var k k8s.K8sValue
+		if err := starlark.UnpackArgs("status", args, kwargs, "k8s", &k); err != nil {
+			return nil, err
+		}
+		status, err := c.defaultStatus(thread, k)
+		if err != nil {
+			return nil, err
+		}
+		result := starlark.NewDict(3)
+		result.SetKey(starlark.String("health"), starlark.String(status.Health))
+		result.SetKey(starlark.String("endpoints"), stringsToList(status.Endpoints))
+		result.SetKey(starlark.String("messages"), stringsToList(status.Messages))
+		return result, nil
+	})
+}
+
+func stringsToList(values []string) *starlark.List {
+	list := make([]starlark.Value, 0, len(values))
+	for _, v := range values {
+		list = append(list, starlark.String(v))
+	}
+	return starlark.NewList(list)
+}
+
+// defaultStatus summarizes the readiness of the objects rendered by the template of the chart
+func (c *chartImpl) defaultStatus(thread *starlark.Thread, k k8s.K8sValue) (*ChartStatus, error) {
+	status := &ChartStatus{Health: HealthReady, Endpoints: []string{}, Messages: []string{}}
+	ready, desired, workloads := 0, 0, 0
+	err := k8s.Decode(c.template(thread, "", k)).Filter(func(obj *k8s.Object) bool {
+		return obj.Kind != "" && !isHook(obj)
+	})(func(obj *k8s.Object) error {
+		namespace := obj.MetaData.Namespace
+		if namespace == "" {
+			namespace = c.namespace
+		}
+		current, err := k.Get(obj.Kind, obj.MetaData.Name, &k8s.Options{Namespace: namespace, IgnoreNotFound: true, Quiet: true})
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("%s/%s", strings.ToLower(obj.Kind), obj.MetaData.Name)
+		if current == nil {
+			status.Health = HealthMissing
+			status.Messages = append(status.Messages, name+" is missing")
+			return nil
+		}
+		r := readinessOf(current)
+		if r.workload {
+			workloads++
+			ready += r.ready
+			desired += r.desired
+		}
+		if !r.isReady() {
+			if status.Health == HealthReady {
+				status.Health = HealthProgressing
+			}
+			status.Messages = append(status.Messages, fmt.Sprintf("%s %d/%d ready", name, r.ready, r.desired))
+		}
+		status.Endpoints = append(status.Endpoints, r.endpoints...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if workloads != 0 {
+		status.Messages = append([]string{fmt.Sprintf("%d/%d pods", ready, desired)}, status.Messages...)
+	}
+	return status, nil
+}
+
+type readiness struct {
+	workload  bool
+	ready     int
+	desired   int
+	endpoints []string
+}
+
+func (r readiness) isReady() bool {
+	return r.ready >= r.desired
+}
+
+// readinessOf checks the status of workloads, jobs and pods and collects endpoints of ingresses and load balancers
+func readinessOf(obj *k8s.Object) readiness {
+	var content struct {
+		Spec struct {
+			Replicas *int `json:"replicas"`
+			Rules    []struct {
+				Host string `json:"host"`
+			} `json:"rules"`
+			TLS []struct {
+				Hosts []string `json:"hosts"`
+			} `json:"tls"`
+		} `json:"spec"`
+		Status struct {
+			ReadyReplicas          int    `json:"readyReplicas"`
+			DesiredNumberScheduled int    `json:"desiredNumberScheduled"`
+			NumberReady            int    `json:"numberReady"`
+			Succeeded              int    `json:"succeeded"`
+			Phase                  string `json:"phase"`
+			LoadBalancer           struct {
+				Ingress []struct {
+					IP       string `json:"ip"`
+					Hostname string `json:"hostname"`
+				} `json:"ingress"`
+			} `json:"loadBalancer"`
+		} `json:"status"`
+	}
+	if data, err := json.Marshal(obj); err == nil {
+		json.Unmarshal(data, &content)
+	}
+	r := readiness{}
+	switch strings.ToLower(obj.Kind) {
+	case "deployment", "statefulset", "replicaset":
+		r.workload = true
+		r.desired = 1
+		if content.Spec.Replicas != nil {
+			r.desired = *content.Spec.Replicas
+		}
+		r.ready = content.Status.ReadyReplicas
+	case "daemonset":
+		r.workload = true
+		r.desired = content.Status.DesiredNumberScheduled
+		r.ready = content.Status.NumberReady
+	case "pod":
+		r.workload = true
+		r.desired = 1
+		if content.Status.Phase == "Running" || content.Status.Phase == "Succeeded" {
+			r.ready = 1
+		}
+	case "job":
+		r.desired = 1
+		if content.Status.Succeeded > 0 {
+			r.ready = 1
+		}
+	case "ingress":
+		tls := map[string]bool{}
+		for _, t := range content.Spec.TLS {
+			for _, host := range t.Hosts {
+				tls[host] = true
+			}
+		}
+		for _, rule := range content.Spec.Rules {
+			if rule.Host == "" {
+				continue
+			}
+			if tls[rule.Host] {
+				r.endpoints = append(r.endpoints, "https://"+rule.Host)
+			} else {
+				r.endpoints = append(r.endpoints, "http://"+rule.Host)
+			}
+		}
+	case "service":
+		for _, ingress := range content.Status.LoadBalancer.Ingress {
+			if ingress.Hostname != "" {
+				r.endpoints = append(r.endpoints, ingress.Hostname)
+			} else if ingress.IP != "" {
+				r.endpoints = append(r.endpoints, ingress.IP)
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,100 @@
+package kdo
+
+import (
+	"encoding/json"
+
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Status", func() {
+	var dir TestDir
+	thread := &starlark.Thread{Name: "main"}
+
+	object := func(j string) k8s.Object {
+		var obj k8s.Object
+		Expect(json.Unmarshal([]byte(j), &obj)).To(Succeed())
+		return obj
+	}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		dir.MkdirAll("templates", 0755)
+		dir.WriteFile("Chart.yaml", []byte("name: uaa\nversion: 1.0.0\n"), 0644)
+		dir.WriteFile("templates/uaa.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: uaa
+spec:
+  replicas: 3
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: uaa
+spec:
+  tls:
+  - hosts: [uaa.example.com]
+  rules:
+  - host: uaa.example.com
+`), 0644)
+		dir.MkdirAll("sub/templates", 0755)
+		dir.WriteFile("sub/Chart.star", []byte(`
+def init(self):
+	pass
+def status(self, k8s):
+	s = self.__status(k8s)
+	s["messages"].append("custom")
+	return s
+`), 0644)
+		dir.WriteFile("sub/templates/job.yaml", []byte(`
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+`), 0644)
+		dir.WriteFile("Chart.star", []byte(`
+def init(self):
+	self.sub = chart("sub")
+`), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	It("summarizes the readiness of the objects of the chart", func() {
+		k := k8s.NewK8sInMemory("test",
+			object(`{"kind":"Deployment","metadata":{"name":"uaa","namespace":"test"},"spec":{"replicas":3},"status":{"readyReplicas":3}}`),
+			object(`{"kind":"Ingress","metadata":{"name":"uaa","namespace":"test"},"spec":{"tls":[{"hosts":["uaa.example.com"]}],"rules":[{"host":"uaa.example.com"}]}}`),
+			object(`{"kind":"Job","metadata":{"name":"migrate","namespace":"test"},"status":{"succeeded":1}}`),
+		)
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), WithNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		status, err := c.Status(thread, k)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.String()).To(Equal("uaa: ready, 3/3 pods, endpoint https://uaa.example.com"))
+		Expect(status.Subcharts).To(HaveLen(1))
+		Expect(status.Subcharts[0].String()).To(Equal("sub: ready, custom"))
+		Expect(status.Ready()).To(BeTrue())
+	})
+
+	It("reports missing and progressing objects", func() {
+		k := k8s.NewK8sInMemory("test",
+			object(`{"kind":"Deployment","metadata":{"name":"uaa","namespace":"test"},"spec":{"replicas":3},"status":{"readyReplicas":1}}`),
+		)
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), WithNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		status, err := c.Status(thread, k)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Health).To(Equal(HealthMissing))
+		Expect(status.Messages).To(Equal([]string{"1/3 pods", "deployment/uaa 1/3 ready", "ingress/uaa is missing"}))
+		Expect(status.Subcharts[0].Messages).To(Equal([]string{"job/migrate is missing", "custom"}))
+		Expect(status.Ready()).To(BeFalse())
+	})
+})
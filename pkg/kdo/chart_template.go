@@ -2,12 +2,14 @@ package kdo
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
@@ -66,8 +68,11 @@ type capabilities struct {
 }
 
 func (c *chartImpl) Template(thread *starlark.Thread, k k8s.K8s) k8s.Stream {
+	if err := c.validate(); err != nil {
+		return k8s.ErrorStream(fmt.Errorf("invalid values for chart %s: %w", c.GetName(), err))
+	}
 	streams := []k8s.Stream{}
-	err := c.eachSubChart(func(subChart *chartImpl) error {
+	err := c.subChartGraph(false).run(1, func(subChart *chartImpl) error {
 		streams = append(streams, subChart.template(thread, "", k))
 		return nil
 	})
@@ -75,10 +80,44 @@ func (c *chartImpl) Template(thread *starlark.Thread, k k8s.K8s) k8s.Stream {
 		return k8s.ErrorStream(err)
 	}
 	streams = append(streams, c.template(thread, "", k))
-	return k8s.YamlConcat(streams...)
+	return func(w io.Writer) error {
+		return c.withTreeRender(func() error {
+			return k8s.YamlConcat(streams...)(w)
+		})
+	}
 }
 
+// template returns the objects rendered by the chart. While the chart tree is post rendered, these are the post
+// rendered objects of the chart, which the glob selects.
 func (c *chartImpl) template(thread *starlark.Thread, glob string, k k8s.K8s) k8s.Stream {
+	return func(w io.Writer) error {
+		if c.treeRender == nil {
+			return c.renderLocal(thread, glob, k)(w)
+		}
+		s := c.treeRender.objectsOf(thread, c, k)
+		if glob == "" {
+			return s(w)
+		}
+		selected := map[string]bool{}
+		err := k8s.Decode(c.renderLocal(thread, glob, k))(func(obj *k8s.Object) error {
+			selected[objectKey(obj)] = true
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return k8s.Decode(s).Filter(func(obj *k8s.Object) bool {
+			return selected[objectKey(obj)]
+		}).Encode()(w)
+	}
+}
+
+func objectKey(obj *k8s.Object) string {
+	return obj.Kind + "/" + obj.MetaData.Namespace + "/" + obj.MetaData.Name
+}
+
+// renderLocal calls the template method of the chart
+func (c *chartImpl) renderLocal(thread *starlark.Thread, glob string, k k8s.K8s) k8s.Stream {
 	kwargs := []starlark.Tuple{}
 	template := c.methods["template"]
 	templateFunction, ok := template.(*chartMethod)
@@ -129,11 +168,29 @@ func (c *chartImpl) templateFunction() starlark.Callable {
 					kwargs: starlark.StringDict{"self": c, "k8s": k8s.NewK8sValue(k)},
 				}}))
 		}
+		kustomizeDir := path.Join(c.dir, "kustomize")
+		if _, err := os.Stat(kustomizeDir); err == nil {
+			s = k8s.YamlConcat(s, c.kustomizeTemplate(kustomizeDir))
+		}
 		return k8s.NewStreamValue(s), nil
 	})
 }
 
 func (c *chartImpl) helmTemplate(thread *starlark.Thread, dir string, glob string, k k8s.K8s) k8s.Stream {
+	helmFileRenderer := c.helmFileRenderer(thread, k)
+	return func(writer io.Writer) error {
+
+		return renderer.DirRender(glob,
+			renderer.DirSpec{
+				Dir:          path.Join(c.dir, dir),
+				FileRenderer: helmFileRenderer,
+			})(writer)
+
+	}
+}
+
+// helmFileRenderer renders files of the chart with the template data of helm
+func (c *chartImpl) helmFileRenderer(thread *starlark.Thread, k k8s.K8s) func(filename string) func(writer io.Writer) error {
 	values := starutils.StringDictToGo(c.values)
 	methods := make(map[string]interface{})
 	for k, f := range c.methods {
@@ -143,7 +200,7 @@ func (c *chartImpl) helmTemplate(thread *starlark.Thread, dir string, glob strin
 			return value, err
 		}
 	}
-	helmFileRenderer := renderer.HelmFileRenderer(c.path(), struct {
+	return renderer.HelmFileRenderer(c.path(), struct {
 		Values       interface{}
 		Methods      map[string]interface{}
 		Chart        chart
@@ -182,17 +239,7 @@ func (c *chartImpl) helmTemplate(thread *starlark.Thread, dir string, glob strin
 		},
 		Files: renderer.Files{Dir: c.dir},
 		K8s:   k,
-	})
-
-	return func(writer io.Writer) error {
-
-		return renderer.DirRender(glob,
-			renderer.DirSpec{
-				Dir:          path.Join(c.dir, dir),
-				FileRenderer: helmFileRenderer,
-			})(writer)
-
-	}
+	}, k8sLookup(k))
 }
 
 func (c *chartImpl) yttTemplate(thread *starlark.Thread, fileTuple starlark.Tuple) k8s.Stream {
@@ -316,6 +363,37 @@ func (c *chartImpl) yttTemplateFunction() starlark.Callable {
 	})
 }
 
+// kustomizeTemplate runs the kustomization in dir with the objects of resources as additional resources
+func (c *chartImpl) kustomizeTemplate(dir string, resources ...k8s.Stream) k8s.Stream {
+	return func(writer io.Writer) error {
+		buffer := &bytes.Buffer{}
+		if err := k8s.YamlConcat(resources...)(buffer); err != nil {
+			return err
+		}
+		return renderer.KustomizeRender(dir, buffer.Bytes())(writer)
+	}
+}
+
+func (c *chartImpl) kustomizeTemplateFunction() starlark.Callable {
+	return c.builtin("kustomize", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("kustomize: missing argument for dir")
+		}
+		if len(kwargs) != 0 {
+			return nil, fmt.Errorf("kustomize: unexpected keyword arguments")
+		}
+		dir, ok := args[0].(starlark.String)
+		if !ok {
+			return nil, fmt.Errorf("kustomize: for parameter dir: got %s, want string", args[0].Type())
+		}
+		resources := []k8s.Stream{}
+		for _, arg := range args[1:] {
+			resources = append(resources, k8s.ToStream(arg, nil))
+		}
+		return k8s.NewStreamValue(c.kustomizeTemplate(path.Join(c.dir, dir.GoString()), resources...)), nil
+	})
+}
+
 func (c *chartImpl) jewelStream() k8s.ObjectStream {
 	return func(w k8s.ObjectConsumer) error {
 		vault := &vaultK8s{objectWriter: w, namespace: c.namespace}
@@ -325,6 +403,33 @@ func (c *chartImpl) jewelStream() k8s.ObjectStream {
 	}
 }
 
+// k8sLookup implements the helm function lookup. Objects which don't exist are returned as empty map.
+func k8sLookup(k k8s.K8s) renderer.LookupFunction {
+	return func(apiVersion string, kind string, namespace string, name string) (map[string]interface{}, error) {
+		resource := strings.ToLower(kind)
+		if gv := strings.SplitN(apiVersion, "/", 2); len(gv) == 2 {
+			resource += "." + gv[0]
+		}
+		options := &k8s.Options{Namespace: namespace, IgnoreNotFound: true, Quiet: true, ClusterScoped: namespace == ""}
+		var obj *k8s.Object
+		var err error
+		if name == "" {
+			obj, err = k.List(resource, options, &k8s.ListOptions{AllNamespaces: namespace == ""})
+		} else {
+			obj, err = k.Get(resource, name, options)
+		}
+		result := map[string]interface{}{}
+		if err != nil || obj == nil {
+			return result, err
+		}
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return result, err
+		}
+		return result, json.Unmarshal(data, &result)
+	}
+}
+
 func k8sFromValue(v starlark.Value) k8s.K8s {
 	result, ok := v.(k8s.K8sValue)
 	if ok {
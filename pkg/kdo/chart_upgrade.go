@@ -0,0 +1,90 @@
+package kdo
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+)
+
+type migration struct {
+	constraint *semver.Constraints
+	fn         starlark.Callable
+}
+
+// makeMigrations converts the migrations dict of Chart.star. Keys are version constraints, values are functions
+// called with self, k8s and the installed version.
+func makeMigrations(value starlark.Value) ([]migration, error) {
+	dict, ok := value.(*starlark.Dict)
+	if !ok {
+		return nil, fmt.Errorf("migrations must be a dict, got %s", value.Type())
+	}
+	result := make([]migration, 0, dict.Len())
+	for _, item := range dict.Items() {
+		key, ok := starlark.AsString(item.Index(0))
+		if !ok {
+			return nil, fmt.Errorf("key %s of migrations must be a string", item.Index(0))
+		}
+		constraint, err := semver.NewConstraint(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q in migrations: %s", key, err.Error())
+		}
+		fn, ok := item.Index(1).(starlark.Callable)
+		if !ok {
+			return nil, fmt.Errorf("migration %q must be a function, got %s", key, item.Index(1).Type())
+		}
+		result = append(result, migration{constraint: constraint, fn: fn})
+	}
+	return result, nil
+}
+
+// installedVersion returns the version recorded in the config map of the chart or nil if the chart isn't installed
+func (c *chartImpl) installedVersion(k k8s.K8sValue) (*semver.Version, error) {
+	obj, err := k.Get("configmap", c.objName(), &k8s.Options{IgnoreNotFound: true, Quiet: true})
+	if err != nil || obj == nil {
+		return nil, err
+	}
+	version := obj.MetaData.Labels["kdo.sap.github.com/version"]
+	data := map[string]string{}
+	if raw, ok := obj.Additional["data"]; ok && json.Unmarshal(raw, &data) == nil && data["version"] != "" {
+		version = data["version"]
+	}
+	if version == "" {
+		return nil, nil
+	}
+	return semver.NewVersion(version)
+}
+
+// upgrade runs the migrations and the upgrade method of the chart, if the installed version differs from the version
+// of the chart. Downgrades are refused unless allowDowngrade is set.
+func (c *chartImpl) upgrade(thread *starlark.Thread, k k8s.K8sValue) error {
+	if c.skipChart {
+		return nil
+	}
+	from, err := c.installedVersion(k)
+	if err != nil {
+		return err
+	}
+	to := c.GetVersion()
+	if from == nil || from.Equal(to) {
+		return nil
+	}
+	if from.GreaterThan(to) && !c.allowDowngrade {
+		return fmt.Errorf("chart %s is installed in version %s, downgrade to %s isn't allowed (use --allow-downgrade)", c.GetName(), from, to)
+	}
+	fromVersion := starlark.String(from.String())
+	if from.GreaterThan(to) {
+		return c.hook(thread, "upgrade", k, fromVersion)
+	}
+	for _, m := range c.migrations {
+		if !m.constraint.Check(from) || m.constraint.Check(to) {
+			continue
+		}
+		if _, err := starlark.Call(thread, m.fn, starlark.Tuple{c, k, fromVersion}, nil); err != nil {
+			return fmt.Errorf("migration %s of chart %s failed: %w", m.constraint, c.GetName(), err)
+		}
+	}
+	return c.hook(thread, "upgrade", k, fromVersion)
+}
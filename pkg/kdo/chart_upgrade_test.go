@@ -0,0 +1,90 @@
+package kdo
+
+import (
+	"fmt"
+
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/starutils"
+)
+
+var _ = Describe("Upgrade", func() {
+	var dir TestDir
+	var k *k8s.K8sInMemory
+	thread := &starlark.Thread{Name: "main"}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		k = k8s.NewK8sInMemory("test")
+		dir.WriteFile("Chart.star", []byte(`
+def init(self):
+	self.events = []
+def to_2(self, k8s, from_version):
+	self.events.append("to_2 from " + from_version)
+def to_3(self, k8s, from_version):
+	self.events.append("to_3 from " + from_version)
+migrations = {
+	"<2.0.0": to_2,
+	"<3.0.0": to_3,
+}
+def upgrade(self, k8s, from_version):
+	self.events.append("upgrade from " + from_version)
+`), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	apply := func(version string, options ...ChartOption) ([]string, error) {
+		dir.WriteFile("Chart.yaml", []byte(fmt.Sprintf("name: test\nversion: %s\n", version)), 0644)
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), options...)
+		Expect(err).NotTo(HaveOccurred())
+		err = c.Apply(thread, k)
+		return starutils.ToGoStringList(c.values["events"]), err
+	}
+
+	It("calls nothing on first install and for the same version", func() {
+		Expect(apply("1.0.0")).To(BeEmpty())
+		Expect(apply("1.0.0")).To(BeEmpty())
+	})
+
+	It("calls matching migrations and upgrade", func() {
+		Expect(apply("1.0.0")).To(BeEmpty())
+		Expect(apply("2.1.0")).To(Equal([]string{"to_2 from 1.0.0", "upgrade from 1.0.0"}))
+		Expect(apply("3.0.0")).To(Equal([]string{"to_3 from 2.1.0", "upgrade from 2.1.0"}))
+	})
+
+	It("refuses downgrades", func() {
+		Expect(apply("2.0.0")).To(BeEmpty())
+		_, err := apply("1.0.0")
+		Expect(err).To(MatchError(ContainSubstring("downgrade to 1.0.0 isn't allowed")))
+		Expect(apply("1.0.0", WithAllowDowngrade(true))).To(Equal([]string{"upgrade from 2.0.0"}))
+	})
+
+	It("skips migrations on downgrades", func() {
+		dir.WriteFile("Chart.star", []byte(`
+def init(self):
+	self.events = []
+def from_2(self, k8s, from_version):
+	self.events.append("from_2 from " + from_version)
+migrations = {
+	">=2.0.0": from_2,
+}
+def upgrade(self, k8s, from_version):
+	self.events.append("upgrade from " + from_version)
+`), 0644)
+		Expect(apply("2.1.0")).To(BeEmpty())
+		Expect(apply("1.5.0", WithAllowDowngrade(true))).To(Equal([]string{"upgrade from 2.1.0"}))
+	})
+
+	It("rejects invalid migrations", func() {
+		dir.WriteFile("Chart.star", []byte(`migrations = {"not a version": None}`), 0644)
+		repo, _ := NewRepo()
+		_, err := newChart(thread, repo, dir.Root())
+		Expect(err).To(MatchError(ContainSubstring(`invalid version constraint "not a version"`)))
+	})
+})
@@ -0,0 +1,50 @@
+package kdo
+
+import (
+	"context"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+)
+
+const contextLocal = "context"
+
+// SetContext sets the context which cancels the execution of charts on the given thread
+func SetContext(thread *starlark.Thread, ctx context.Context) {
+	thread.SetLocal(contextLocal, ctx)
+}
+
+// GetContext returns the context of the given thread or context.Background() if no context is set
+func GetContext(thread *starlark.Thread) context.Context {
+	ctx, ok := thread.Local(contextLocal).(context.Context)
+	if !ok || ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+// inheritContext uses the context of k for thread, if no context is set yet
+func inheritContext(thread *starlark.Thread, k k8s.K8s) {
+	if thread.Local(contextLocal) != nil {
+		return
+	}
+	if ctx := k.Context(); ctx != nil {
+		SetContext(thread, ctx)
+	}
+}
+
+// cancellable stops calling fn as soon as the context of the thread is done. Errors caused by the cancellation
+// are reported as k8s.CancelledError naming the chart and the operation.
+func (c *chartImpl) cancellable(operation string, fn func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error)) func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		ctx := GetContext(thread)
+		if err := ctx.Err(); err != nil {
+			return starlark.None, &k8s.CancelledError{Chart: c.GetName(), Operation: operation, Err: err}
+		}
+		value, err := fn(thread, b, args, kwargs)
+		if _, ok := err.(*starlark.EvalError); err != nil && !ok {
+			err = k8s.Cancelled(ctx, c.GetName(), operation, err)
+		}
+		return value, err
+	}
+}
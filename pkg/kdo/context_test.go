@@ -0,0 +1,59 @@
+package kdo
+
+import (
+	"context"
+
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Context", func() {
+	var dir TestDir
+	var repo Repo
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		repo, _ = NewRepo()
+		dir.MkdirAll("db/templates", 0755)
+		dir.WriteFile("db/Chart.yaml", []byte("name: db\nversion: 1.0.0\n"), 0644)
+		dir.WriteFile("Chart.star", []byte(`
+def init(self):
+  self.db = chart("db")
+
+def apply(self,k8s):
+  self.db.apply(k8s)
+`), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	It("cancels apply if the context is done", func() {
+		thread := &starlark.Thread{Name: "main"}
+		c, err := newChart(thread, repo, dir.Root(), WithSkipChart(true))
+		Expect(err).NotTo(HaveOccurred())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		k := k8s.NewK8sInMemory("test").WithContext(ctx)
+		err = c.Apply(thread, k)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("cancelled in chart " + c.GetName() + " while doing apply: context canceled"))
+		Expect(GetContext(thread)).To(Equal(ctx))
+	})
+
+	It("reports errors caused by the cancellation", func() {
+		c := &chartImpl{clazz: chartClass{Name: "db"}}
+		ctx, cancel := context.WithCancel(context.Background())
+		thread := &starlark.Thread{Name: "main"}
+		SetContext(thread, ctx)
+		fn := c.builtin("wait", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			cancel()
+			return starlark.None, context.Canceled
+		})
+		_, err := starlark.Call(thread, fn, nil, nil)
+		Expect(err).To(MatchError(ContainSubstring("cancelled in chart db while doing wait: context canceled")))
+	})
+})
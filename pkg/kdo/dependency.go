@@ -16,7 +16,9 @@ type dependency struct {
 	url        string
 	constraint *semver.Constraints
 	namespace  string
+	suffix     string
 	userBy     func() string
+	provider   KeyProvider
 }
 
 var _ starlark.HasAttrs = (*dependency)(nil)
@@ -24,13 +26,13 @@ var _ starlark.HasSetField = (*dependency)(nil)
 var _ Property = (*dependency)(nil)
 var _ starutils.GoConvertible = (*dependency)(nil)
 
-func makeDependency(userBy func() string, repo Repo, namespace string) func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
+func makeDependency(userBy func() string, repo Repo, namespace string, provider KeyProvider) func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
 
 	return func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
-		s := &dependency{properties: newStructProperty(true), namespace: namespace, repo: repo, userBy: userBy}
+		s := &dependency{properties: newStructProperty(true), namespace: namespace, repo: repo, userBy: userBy, provider: provider}
 		var err error
 		var constraint string
-		if err = starlark.UnpackArgs("dependency", args, kwargs, "url", &s.url, "constraint", &constraint, "namespace?", &s.namespace); err != nil {
+		if err = starlark.UnpackArgs("dependency", args, kwargs, "url", &s.url, "constraint", &constraint, "namespace?", &s.namespace, "suffix?", &s.suffix); err != nil {
 			return nil, err
 		}
 		s.constraint, err = semver.NewConstraint(constraint)
@@ -99,7 +101,7 @@ func (s *dependency) Apply(thread *starlark.Thread, k8s k8s.K8s) error {
 		return nil
 	}
 	gv := NewGenusAndVersion(s.url)
-	charts, err := s.repo.List(thread, k8s, &RepoListOptions{namespace: s.namespace, genus: gv.genus})
+	charts, err := s.repo.List(thread, k8s, s.listOptions(gv))
 	if err != nil {
 		return err
 	}
@@ -116,7 +118,7 @@ func (s *dependency) Apply(thread *starlark.Thread, k8s k8s.K8s) error {
 		}
 		return s.resolve(charts[0])
 	}
-	chart, err := s.repo.Get(thread, s.url, append(gv.AsOptions(), WithNamespace(s.namespace))...)
+	chart, err := s.repo.Get(thread, s.url, append(gv.AsOptions(), WithNamespace(s.namespace), WithSuffix(s.suffix))...)
 	if err != nil {
 		return err
 	}
@@ -135,13 +137,17 @@ func (s *dependency) Apply(thread *starlark.Thread, k8s k8s.K8s) error {
 	return nil
 }
 
+func (s *dependency) listOptions(gv *GenusAndVersion) *RepoListOptions {
+	return &RepoListOptions{namespace: s.namespace, genus: gv.genus, suffix: s.suffix, matchSuffix: true, keyProvider: s.provider}
+}
+
 func (s *dependency) Delete(thread *starlark.Thread, k8s k8s.K8s, deleteOptions *DeleteOptions) error {
 	_, ok := s.properties.(ChartValue)
 	if ok {
 		return nil
 	}
 	gv := NewGenusAndVersion(s.url)
-	charts, err := s.repo.List(thread, k8s, &RepoListOptions{namespace: s.namespace, genus: gv.genus})
+	charts, err := s.repo.List(thread, k8s, s.listOptions(gv))
 	if err != nil {
 		return err
 	}
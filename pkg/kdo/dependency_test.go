@@ -11,7 +11,7 @@ var _ = Describe("Dependency", func() {
 	It("behaves like starlark value", func() {
 		thread := &starlark.Thread{Name: "main"}
 		args := starlark.Tuple{starlark.String("url"), starlark.String(">= 1.0")}
-		d, err := makeDependency(nil, nil, "")(thread, nil, args, nil)
+		d, err := makeDependency(nil, nil, "", nil)(thread, nil, args, nil)
 		Expect(err).NotTo(HaveOccurred())
 		s := d.(*dependency)
 		Expect(s.String()).To(ContainSubstring("url = url"))
@@ -0,0 +1,326 @@
+package kdo
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// encryptedValuesKey is the key of the encrypted values in the secret of a chart. Property names can't contain dots.
+const encryptedValuesKey = "kdo.encrypted"
+
+// errEncryptionKeyRequired is returned when encrypted values are read without key provider
+var errEncryptionKeyRequired = errors.New("an encryption key is required (use --encryption-key)")
+
+// KeyProvider wraps and unwraps the data encryption keys of persisted chart values
+type KeyProvider interface {
+	// Name is the name of the provider stored in the envelope, e.g. file
+	Name() string
+	// WrapKey encrypts a data encryption key and returns it together with the id of the key used
+	WrapKey(key []byte) (wrapped []byte, keyID string, err error)
+	// UnwrapKey decrypts a data encryption key wrapped with the key with the given id
+	UnwrapKey(wrapped []byte, keyID string) ([]byte, error)
+}
+
+// envelope is the format of encrypted values
+type envelope struct {
+	Provider   string `json:"provider"`
+	KeyID      string `json:"keyId"`
+	WrappedKey []byte `json:"wrappedKey"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// NewKeyProvider creates a key provider from a url:
+//
+//	file://<path>  static 32 byte key, raw, hex or base64 encoded
+//	age://<path>   age identity file, e.g. created by age-keygen
+//	kms://<path>   local key ring emulating a key management service, created by kdo rekey --rotate
+//
+// A path without scheme is treated as file.
+func NewKeyProvider(keyURL string) (KeyProvider, error) {
+	return newKeyProvider(keyURL, false)
+}
+
+// newKeyProvider creates a key provider like NewKeyProvider, a missing key ring is created empty if create is true
+func newKeyProvider(keyURL string, create bool) (KeyProvider, error) {
+	scheme, path := "file", keyURL
+	if u, err := url.Parse(keyURL); err == nil && u.Scheme != "" {
+		scheme, path = u.Scheme, u.Host+u.Path
+	}
+	switch scheme {
+	case "file":
+		return newStaticKeyProvider(path)
+	case "age":
+		return newAgeKeyProvider(path)
+	case "kms":
+		return newLocalKMSProvider(path, create)
+	}
+	return nil, fmt.Errorf("unknown encryption key provider %s, must be file, age or kms", scheme)
+}
+
+// encryptValues encrypts data with a new data encryption key, which is wrapped by provider
+func encryptValues(provider KeyProvider, data []byte) ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	wrapped, keyID, err := provider.WrapKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("error wrapping data key with %s provider: %w", provider.Name(), err)
+	}
+	nonce, ciphertext, err := seal(key, data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&envelope{Provider: provider.Name(), KeyID: keyID, WrappedKey: wrapped, Nonce: nonce, Ciphertext: ciphertext})
+}
+
+// decryptValues decrypts data created by encryptValues
+func decryptValues(provider KeyProvider, data []byte) ([]byte, error) {
+	var e envelope
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("invalid encrypted values: %w", err)
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("values are encrypted with %s provider, %w", e.Provider, errEncryptionKeyRequired)
+	}
+	if e.Provider != provider.Name() {
+		return nil, fmt.Errorf("values are encrypted with %s provider, but %s provider is configured", e.Provider, provider.Name())
+	}
+	key, err := provider.UnwrapKey(e.WrappedKey, e.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("error unwrapping data key with %s provider: %w", provider.Name(), err)
+	}
+	return open(key, e.Nonce, e.Ciphertext)
+}
+
+func seal(key []byte, plaintext []byte) ([]byte, []byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	return nonce, gcm.Seal(nil, nonce, plaintext, nil), nil
+}
+
+func open(key []byte, nonce []byte, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce")
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed, wrong key?")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// keyID identifies a key without revealing it
+func keyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// decodeKey accepts raw, hex or base64 encoded 32 byte keys
+func decodeKey(data []byte) ([]byte, error) {
+	if len(data) == 32 {
+		return data, nil
+	}
+	text := strings.TrimSpace(string(data))
+	if key, err := hex.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, fmt.Errorf("key must have 32 bytes (raw, hex or base64 encoded)")
+}
+
+type staticKeyProvider struct {
+	key []byte
+}
+
+func newStaticKeyProvider(path string) (KeyProvider, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := decodeKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key file %s: %w", path, err)
+	}
+	return &staticKeyProvider{key: key}, nil
+}
+
+func (p *staticKeyProvider) Name() string { return "file" }
+
+func (p *staticKeyProvider) WrapKey(key []byte) ([]byte, string, error) {
+	nonce, ciphertext, err := seal(p.key, key)
+	if err != nil {
+		return nil, "", err
+	}
+	return append(nonce, ciphertext...), keyID(p.key), nil
+}
+
+func (p *staticKeyProvider) UnwrapKey(wrapped []byte, id string) ([]byte, error) {
+	if id != keyID(p.key) {
+		return nil, fmt.Errorf("data key is wrapped with key %s, configured key is %s", id, keyID(p.key))
+	}
+	return unwrapWith(p.key, wrapped)
+}
+
+func unwrapWith(key []byte, wrapped []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid wrapped key")
+	}
+	return open(key, wrapped[:gcm.NonceSize()], wrapped[gcm.NonceSize():])
+}
+
+type ageKeyProvider struct {
+	identity *age.X25519Identity
+}
+
+func newAgeKeyProvider(path string) (KeyProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid age identity file %s: %w", path, err)
+	}
+	for _, identity := range identities {
+		if x25519, ok := identity.(*age.X25519Identity); ok {
+			return &ageKeyProvider{identity: x25519}, nil
+		}
+	}
+	return nil, fmt.Errorf("age identity file %s contains no X25519 identity", path)
+}
+
+func (p *ageKeyProvider) Name() string { return "age" }
+
+func (p *ageKeyProvider) WrapKey(key []byte) ([]byte, string, error) {
+	buffer := &bytes.Buffer{}
+	w, err := age.Encrypt(buffer, p.identity.Recipient())
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := w.Write(key); err != nil {
+		return nil, "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buffer.Bytes(), p.identity.Recipient().String(), nil
+}
+
+func (p *ageKeyProvider) UnwrapKey(wrapped []byte, id string) ([]byte, error) {
+	r, err := age.Decrypt(bytes.NewReader(wrapped), p.identity)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(io.LimitReader(r, 1024))
+}
+
+// localKMSProvider emulates a key management service with a key ring file. New data keys are wrapped with the
+// current key, old keys are kept to unwrap existing data keys.
+type localKMSProvider struct {
+	path string
+	ring keyRing
+}
+
+type keyRing struct {
+	Current string            `json:"current"`
+	Keys    map[string][]byte `json:"keys"`
+}
+
+func newLocalKMSProvider(path string, create bool) (KeyProvider, error) {
+	p := &localKMSProvider{path: path}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		if create {
+			return p, nil
+		}
+		return nil, fmt.Errorf("key ring %s doesn't exist, create it with kdo rekey --encryption-key kms://%s --rotate", path, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &p.ring); err != nil {
+		return nil, fmt.Errorf("invalid key ring %s: %w", path, err)
+	}
+	if _, ok := p.ring.Keys[p.ring.Current]; !ok {
+		return nil, fmt.Errorf("invalid key ring %s: current key %s doesn't exist", path, p.ring.Current)
+	}
+	return p, nil
+}
+
+// Rotate adds a new key to the key ring and makes it the current key
+func (p *localKMSProvider) Rotate() error {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	if p.ring.Keys == nil {
+		p.ring.Keys = map[string][]byte{}
+	}
+	p.ring.Current = fmt.Sprintf("v%d", len(p.ring.Keys)+1)
+	p.ring.Keys[p.ring.Current] = key
+	data, err := json.MarshalIndent(&p.ring, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p.path, data, 0600)
+}
+
+func (p *localKMSProvider) Name() string { return "kms" }
+
+func (p *localKMSProvider) WrapKey(key []byte) ([]byte, string, error) {
+	nonce, ciphertext, err := seal(p.ring.Keys[p.ring.Current], key)
+	if err != nil {
+		return nil, "", err
+	}
+	return append(nonce, ciphertext...), p.ring.Current, nil
+}
+
+func (p *localKMSProvider) UnwrapKey(wrapped []byte, id string) ([]byte, error) {
+	key, ok := p.ring.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("key %s doesn't exist in key ring %s", id, p.path)
+	}
+	return unwrapWith(key, wrapped)
+}
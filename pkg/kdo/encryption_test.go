@@ -0,0 +1,152 @@
+package kdo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+
+	"filippo.io/age"
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Encryption", func() {
+	var dir TestDir
+	var k *k8s.K8sInMemory
+	thread := &starlark.Thread{Name: "main"}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		k = k8s.NewK8sInMemory("test")
+		dir.MkdirAll("chart", 0755)
+		dir.WriteFile("chart/Chart.yaml", []byte("name: test\nversion: 1.0.0\n"), 0644)
+		dir.WriteFile("chart/Chart.star", []byte(`
+def init(self):
+	self.password = property(default = "default")
+`), 0644)
+		dir.WriteFile("key", bytes.Repeat([]byte("k"), 32), 0600)
+		dir.WriteFile("other", bytes.Repeat([]byte("o"), 32), 0600)
+		identity, err := age.GenerateX25519Identity()
+		Expect(err).NotTo(HaveOccurred())
+		dir.WriteFile("age.txt", []byte(identity.String()+"\n"), 0600)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	provider := func(url string) KeyProvider {
+		p, err := NewKeyProvider(url)
+		Expect(err).NotTo(HaveOccurred())
+		return p
+	}
+
+	errorOf := func(_ interface{}, err error) error {
+		return err
+	}
+
+	secretData := func() map[string][]byte {
+		obj, err := k.Get("secret", "kdo.test", &k8s.Options{Namespace: "test"})
+		Expect(err).NotTo(HaveOccurred())
+		data := map[string][]byte{}
+		Expect(json.Unmarshal(obj.Additional["data"], &data)).To(Succeed())
+		return data
+	}
+
+	apply := func(options ...ChartOption) {
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Join("chart"), append([]ChartOption{WithNamespace("test")}, options...)...)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Apply(thread, k)).To(Succeed())
+	}
+
+	reused := func(options ...ChartOption) (starlark.Value, error) {
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Join("chart"), append([]ChartOption{WithNamespace("test"), WithReuseValues(k)}, options...)...)
+		if err != nil {
+			return nil, err
+		}
+		return c.Attr("password")
+	}
+
+	It("encrypts and decrypts values with all providers", func() {
+		Expect(errorOf(NewKeyProvider("kms://" + dir.Join("keyring.json")))).To(MatchError(ContainSubstring("key ring " + dir.Join("keyring.json") + " doesn't exist")))
+		options := &RekeyOptions{RepoListOptions: RepoListOptions{namespace: "test", encryptionKey: "kms://" + dir.Join("keyring.json")}, rotate: true}
+		Expect(Rekey(k, options, &bytes.Buffer{})).To(Succeed())
+		for _, url := range []string{dir.Join("key"), "file://" + dir.Join("key"), "age://" + dir.Join("age.txt"), "kms://" + dir.Join("keyring.json")} {
+			p := provider(url)
+			encrypted, err := encryptValues(p, []byte("secret"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(encrypted)).NotTo(ContainSubstring("secret"))
+			Expect(decryptValues(p, encrypted)).To(Equal([]byte("secret")))
+			Expect(errorOf(decryptValues(nil, encrypted))).To(MatchError(ContainSubstring("an encryption key is required")))
+		}
+		encrypted, _ := encryptValues(provider(dir.Join("key")), []byte("secret"))
+		Expect(errorOf(decryptValues(provider(dir.Join("other")), encrypted))).To(HaveOccurred())
+		Expect(errorOf(decryptValues(provider("age://"+dir.Join("age.txt")), encrypted))).To(MatchError(ContainSubstring("encrypted with file provider")))
+		Expect(errorOf(NewKeyProvider("vault://key"))).To(MatchError(ContainSubstring("unknown encryption key provider vault")))
+	})
+
+	It("stores encrypted values and decrypts them on reuse and list", func() {
+		apply(WithValues(map[string]interface{}{"password": "s3cr3t"}), WithEncryption(provider(dir.Join("key"))))
+		data := secretData()
+		Expect(data).To(HaveLen(1))
+		Expect(data).To(HaveKey(encryptedValuesKey))
+		Expect(string(data[encryptedValuesKey])).NotTo(ContainSubstring("s3cr3t"))
+
+		Expect(reused(WithEncryption(provider(dir.Join("key"))))).To(Equal(starlark.String("s3cr3t")))
+		_, err := reused()
+		Expect(err).To(MatchError(ContainSubstring("an encryption key is required")))
+
+		repo, _ := NewRepo()
+		charts, err := repo.List(thread, k, &RepoListOptions{namespace: "test", encryptionKey: dir.Join("key")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(charts).To(HaveLen(1))
+		Expect(charts[0].Attr("password")).To(Equal(starlark.String("s3cr3t")))
+		charts, err = repo.List(thread, k, &RepoListOptions{namespace: "test"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(charts[0].Attr("password")).To(Equal(starlark.String("default")))
+	})
+
+	It("refuses to store encrypted values unencrypted", func() {
+		apply(WithValues(map[string]interface{}{"password": "s3cr3t"}), WithEncryption(provider(dir.Join("key"))))
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Join("chart"), WithNamespace("test"), WithValues(map[string]interface{}{"password": "plain"}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Apply(thread, k)).To(MatchError(ContainSubstring("values in secret kdo.test are encrypted")))
+		Expect(secretData()).To(HaveKey(encryptedValuesKey))
+
+		apply(WithValues(map[string]interface{}{"password": "plain"}), WithAllowUnencrypted(true))
+		Expect(string(secretData()["password"])).To(Equal(`"plain"`))
+	})
+
+	It("rekeys installed charts", func() {
+		apply(WithValues(map[string]interface{}{"password": "s3cr3t"}))
+		Expect(secretData()).To(HaveKey("password"))
+		writer := &bytes.Buffer{}
+
+		options := &RekeyOptions{RepoListOptions: RepoListOptions{namespace: "test", encryptionKey: dir.Join("key")}}
+		Expect(Rekey(k, options, writer)).To(Succeed())
+		Expect(writer.String()).To(Equal("Re-encrypted values of chart test in namespace test\n"))
+		Expect(secretData()).NotTo(HaveKey("password"))
+
+		options = &RekeyOptions{RepoListOptions: RepoListOptions{namespace: "test", encryptionKey: "kms://" + dir.Join("keyring.json")}, oldEncryptionKey: dir.Join("key")}
+		Expect(Rekey(k, options, writer)).To(MatchError(ContainSubstring("doesn't exist")))
+		options.rotate = true
+		Expect(Rekey(k, options, writer)).To(Succeed())
+		options = &RekeyOptions{RepoListOptions: RepoListOptions{namespace: "test", encryptionKey: "kms://" + dir.Join("keyring.json")}, rotate: true}
+		Expect(Rekey(k, options, writer)).To(Succeed())
+		ring, err := ioutil.ReadFile(dir.Join("keyring.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(ring)).To(ContainSubstring(`"current": "v2"`))
+		Expect(string(secretData()[encryptedValuesKey])).To(ContainSubstring(`"keyId":"v2"`))
+
+		Expect(reused(WithEncryption(provider("kms://" + dir.Join("keyring.json"))))).To(Equal(starlark.String("s3cr3t")))
+		Expect(errorOf(reused(WithEncryption(provider(dir.Join("key")))))).To(HaveOccurred())
+
+		options = &RekeyOptions{RepoListOptions: RepoListOptions{namespace: "test", encryptionKey: dir.Join("key")}, rotate: true}
+		Expect(Rekey(k, options, writer)).To(MatchError("file provider doesn't support key rotation"))
+	})
+})
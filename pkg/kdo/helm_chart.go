@@ -4,24 +4,40 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/k14s/starlark-go/starlark"
+	"github.com/pkg/errors"
 	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
 	"github.com/sap/kubernetes-deployment-orchestrator/pkg/starutils"
+	"helm.sh/helm/v3/pkg/action"
+	helmchart "helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
+// helmDefaultTimeout is the timeout used by the helm cli if no timeout is given
+const helmDefaultTimeout = 5 * time.Minute
+
 // NewHelmChartFunction -
 func NewHelmChartFunction(repo Repo, dir string, options ...ChartOption) func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
 	return func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
 		var url string
+		var afterValue starlark.Value = starlark.None
 		co := chartOptions(options)
-		if err := starlark.UnpackArgs("chart", args, kwargs, "url", &url, "namespace?", &co.namespace, "suffix?", &co.suffix); err != nil {
+		if err := starlark.UnpackArgs("chart", args, kwargs, "url", &url, "namespace?", &co.namespace, "suffix?", &co.suffix, "after?", &afterValue); err != nil {
+			return starlark.None, err
+		}
+		after, err := parseAfter(afterValue)
+		if err != nil {
 			return starlark.None, err
 		}
 		if !(filepath.IsAbs(url) || strings.HasPrefix(url, "http")) {
@@ -33,9 +49,11 @@ func NewHelmChartFunction(repo Repo, dir string, options ...ChartOption) func(th
 		}
 
 		chart := c.(*chartImpl)
+		chart.after = after
 		chart.methods["apply"] = chart.wrapNamespace(helmApplyFunction(chart))
 		chart.methods["template"] = helmTemplateFunction(chart)
 		chart.methods["delete"] = chart.wrapNamespace(helmDeleteFunction(chart))
+		chart.methods["release_status"] = helmReleaseStatusFunction(chart)
 		return chart, nil
 	}
 }
@@ -43,66 +61,312 @@ func NewHelmChartFunction(repo Repo, dir string, options ...ChartOption) func(th
 func helmApplyFunction(c *chartImpl) starlark.Callable {
 	return c.builtin("apply", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
 		var k k8s.K8sValue
-		if err := starlark.UnpackArgs("apply", args, kwargs, "k8s", &k); err != nil {
+		options := &k8s.Options{}
+		if err := options.UnpackArgs("apply", args, kwargs, "k8s", &k); err != nil {
 			return nil, err
 		}
-		filename, err := values(c)
-		if err != nil {
-			return starlark.None, err
-		}
-		return starlark.None, helm(c, k, &k8s.Options{}, "upgrade", "-i", c.GetName(), c.dir, "-f", filename)
+		return starlark.None, helmUpgrade(thread, c, k, options)
 	})
 }
 
 func helmTemplateFunction(c *chartImpl) starlark.Callable {
 	return c.builtin("template", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
-		var k k8s.K8sValue
-		if err := starlark.UnpackArgs("template", args, kwargs, "k8s", &k); err != nil {
-			return starlark.None, err
-		}
-		filename, err := values(c)
-		if err != nil {
+		var k starlark.Value
+		var glob string
+		if err := starlark.UnpackArgs("template", args, kwargs, "glob?", &glob, "k8s?", &k); err != nil {
 			return starlark.None, err
 		}
-		return starlark.String(""), helm(c, k, &k8s.Options{}, "template", c.dir, "-f", filename)
+		return k8s.NewStreamValue(helmTemplate(c, glob, k8sFromValue(k), &k8s.Options{})), nil
 	})
 }
 
 func helmDeleteFunction(c *chartImpl) starlark.Callable {
-	return c.builtin("apply", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
+	return c.builtin("delete", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
 		var k k8s.K8sValue
-		if err := starlark.UnpackArgs("apply", args, kwargs, "k8s", &k); err != nil {
+		options := &k8s.Options{}
+		if err := options.UnpackArgs("delete", args, kwargs, "k8s", &k); err != nil {
 			return nil, err
 		}
-		_ = helm(c, k, &k8s.Options{}, "uninstall", c.GetName())
-		return starlark.None, nil
+		return starlark.None, helmUninstall(c, k, options)
 	})
 }
 
-func helm(chart *chartImpl, k k8s.K8s, options *k8s.Options, flags ...string) error {
+func helmReleaseStatusFunction(c *chartImpl) starlark.Callable {
+	return c.builtin("release_status", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
+		var k k8s.K8sValue
+		options := &k8s.Options{}
+		if err := options.UnpackArgs("release_status", args, kwargs, "k8s", &k); err != nil {
+			return nil, err
+		}
+		status, err := helmStatus(c, k, options)
+		if err != nil || status == nil {
+			return starlark.None, err
+		}
+		return starutils.WrapDict(starutils.ToStarlark(status)), nil
+	})
+}
+
+// helmConfiguration creates the helm configuration for one invocation. The returned function must be called to release resources.
+var helmConfiguration = func(k k8s.K8s, namespace string) (*action.Configuration, func(), error) {
+	flags := genericclioptions.NewConfigFlags(false)
+	flags.Namespace = &namespace
+	cleanup := func() {}
+	content := k.ConfigContent()
+	if content != nil {
+		f, err := ioutil.TempFile("", "kubeconfig*.yaml")
+		if err != nil {
+			return nil, cleanup, err
+		}
+		cleanup = func() { _ = os.Remove(f.Name()) }
+		_, err = f.WriteString(*content)
+		f.Close()
+		if err != nil {
+			return nil, cleanup, err
+		}
+		kubeConfig := f.Name()
+		flags.KubeConfig = &kubeConfig
+	}
+	config := &action.Configuration{}
+	err := config.Init(flags, namespace, "secret", helmLog)
+	return config, cleanup, err
+}
+
+func helmLog(format string, v ...interface{}) {}
+
+func helmNamespace(c *chartImpl, k k8s.K8s, options *k8s.Options) string {
 	namespace := k.Namespace(options)
-	if namespace != nil {
-		flags = append(flags, "-n", *namespace)
+	if namespace != nil && *namespace != "" {
+		return *namespace
+	}
+	if c.namespace != "" {
+		return c.namespace
 	}
+	return "default"
+}
+
+func helmNotFound(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), driver.ErrReleaseNotFound.Error()) || strings.Contains(err.Error(), "no revision for release"))
+}
+
+// helmTimeout returns the timeout for helm actions. The helm actions don't accept a context, therefore the timeout
+// is limited by the deadline of the context of k. If the context is already done, its error is returned.
+func helmTimeout(k k8s.K8s, options *k8s.Options) (time.Duration, error) {
+	timeout := helmDefaultTimeout
 	if options.Timeout > 0 {
-		flags = append(flags, "--timeout", fmt.Sprintf("%.0fs", options.Timeout.Seconds()))
+		timeout = options.Timeout
 	}
-	cmd := exec.CommandContext(context.TODO(), "helm", flags...)
-	if !options.Quiet {
-		fmt.Println(cmd.String())
+	ctx := k.Context()
+	if ctx == nil {
+		return timeout, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return 0, context.DeadlineExceeded
+		}
+		if remaining < timeout {
+			timeout = remaining
+		}
+	}
+	return timeout, nil
+}
+
+func helmLoad(c *chartImpl) (*helmchart.Chart, map[string]interface{}, error) {
+	chart, err := loader.Load(c.dir)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "loading helm chart %s", c.dir)
 	}
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	values, err := helmValues(c)
+	return chart, values, err
 }
 
-func values(c *chartImpl) (string, error) {
-	filename := "/tmp/values"
+// helmValues converts the current values of the chart into a fresh map for each invocation
+func helmValues(c *chartImpl) (map[string]interface{}, error) {
 	data, err := json.Marshal(starutils.ToGo(c.GetValue()))
 	if err != nil {
-		return filename, err
+		return nil, err
 	}
-	err = ioutil.WriteFile(filename, data, os.FileMode(0755))
-	return filename, err
+	values := map[string]interface{}{}
+	err = json.Unmarshal(data, &values)
+	return values, err
+}
 
+func helmUpgrade(thread *starlark.Thread, c *chartImpl, k k8s.K8s, options *k8s.Options) error {
+	timeout, err := helmTimeout(k, options)
+	if err != nil {
+		return errors.Wrapf(err, "upgrading helm release %s", c.GetName())
+	}
+	chart, values, err := helmLoad(c)
+	if err != nil {
+		return err
+	}
+	namespace := helmNamespace(c, k, options)
+	config, cleanup, err := helmConfiguration(k, namespace)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	if !options.Quiet {
+		fmt.Printf("helm upgrade --install %s %s -n %s\n", c.GetName(), c.dir, namespace)
+	}
+	history := action.NewHistory(config)
+	history.Max = 1
+	if releases, err := history.Run(c.GetName()); err == driver.ErrReleaseNotFound || (err == nil && len(releases) == 0) {
+		install := action.NewInstall(config)
+		install.ReleaseName = c.GetName()
+		install.Namespace = namespace
+		install.Timeout = timeout
+		install.Wait = options.Timeout > 0
+		if c.treeRender != nil {
+			install.PostRenderer = &helmPostRenderer{thread: thread, chart: c, k8s: k}
+		}
+		_, err = install.Run(chart, values)
+		return errors.Wrapf(err, "installing helm release %s", c.GetName())
+	} else if err != nil {
+		return errors.Wrapf(err, "reading history of helm release %s", c.GetName())
+	}
+	upgrade := action.NewUpgrade(config)
+	upgrade.Namespace = namespace
+	upgrade.Timeout = timeout
+	upgrade.Wait = options.Timeout > 0
+	if c.treeRender != nil {
+		upgrade.PostRenderer = &helmPostRenderer{thread: thread, chart: c, k8s: k}
+	}
+	_, err = upgrade.Run(c.GetName(), chart, values)
+	return errors.Wrapf(err, "upgrading helm release %s", c.GetName())
+}
+
+func helmUninstall(c *chartImpl, k k8s.K8s, options *k8s.Options) error {
+	timeout, err := helmTimeout(k, options)
+	if err != nil {
+		return errors.Wrapf(err, "uninstalling helm release %s", c.GetName())
+	}
+	namespace := helmNamespace(c, k, options)
+	config, cleanup, err := helmConfiguration(k, namespace)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	if !options.Quiet {
+		fmt.Printf("helm uninstall %s -n %s\n", c.GetName(), namespace)
+	}
+	uninstall := action.NewUninstall(config)
+	uninstall.Timeout = timeout
+	_, err = uninstall.Run(c.GetName())
+	if helmNotFound(err) {
+		return nil
+	}
+	return errors.Wrapf(err, "uninstalling helm release %s", c.GetName())
+}
+
+// helmTemplate renders the helm chart like helm template. Like for native templates, a glob selects the templates
+// by their file name.
+func helmTemplate(c *chartImpl, glob string, k k8s.K8s, options *k8s.Options) k8s.Stream {
+	return func(w io.Writer) error {
+		chart, values, err := helmLoad(c)
+		if err != nil {
+			return err
+		}
+		if glob != "" {
+			if err := selectHelmTemplates(chart, glob); err != nil {
+				return err
+			}
+		}
+		install := action.NewInstall(&action.Configuration{Log: helmLog})
+		install.ReleaseName = c.GetName()
+		install.Namespace = helmNamespace(c, k, options)
+		install.DryRun = true
+		install.ClientOnly = true
+		install.Replace = true
+		install.IncludeCRDs = true
+		release, err := install.Run(chart, values)
+		if err != nil {
+			return errors.Wrapf(err, "rendering helm chart %s", c.dir)
+		}
+		if _, err := fmt.Fprintln(w, release.Manifest); err != nil {
+			return err
+		}
+		for _, hook := range release.Hooks {
+			if _, err := fmt.Fprintf(w, "---\n# Source: %s\n%s\n", hook.Path, hook.Manifest); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// selectHelmTemplates removes the templates of a chart and its dependencies, whose file name doesn't match glob.
+// Partials, e.g. _helpers.tpl, are kept.
+func selectHelmTemplates(chart *helmchart.Chart, glob string) error {
+	templates := []*helmchart.File{}
+	for _, t := range chart.Templates {
+		name := path.Base(t.Name)
+		match, err := filepath.Match(glob, name)
+		if err != nil {
+			return err
+		}
+		if match || strings.HasPrefix(name, "_") {
+			templates = append(templates, t)
+		}
+	}
+	chart.Templates = templates
+	for _, dependency := range chart.Dependencies() {
+		if err := selectHelmTemplates(dependency, glob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type helmReleaseStatus struct {
+	Name          string `json:"name"`
+	Namespace     string `json:"namespace"`
+	Revision      int    `json:"revision"`
+	Status        string `json:"status"`
+	Description   string `json:"description"`
+	FirstDeployed string `json:"first_deployed"`
+	LastDeployed  string `json:"last_deployed"`
+	Chart         string `json:"chart"`
+	AppVersion    string `json:"app_version"`
+	Notes         string `json:"notes"`
+}
+
+func helmStatus(c *chartImpl, k k8s.K8s, options *k8s.Options) (*helmReleaseStatus, error) {
+	namespace := helmNamespace(c, k, options)
+	config, cleanup, err := helmConfiguration(k, namespace)
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+	release, err := action.NewStatus(config).Run(c.GetName())
+	if err != nil {
+		if helmNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "reading status of helm release %s", c.GetName())
+	}
+	return newHelmReleaseStatus(release), nil
+}
+
+func newHelmReleaseStatus(release *helmrelease.Release) *helmReleaseStatus {
+	status := &helmReleaseStatus{
+		Name:      release.Name,
+		Namespace: release.Namespace,
+		Revision:  release.Version,
+	}
+	if release.Info != nil {
+		status.Status = release.Info.Status.String()
+		status.Description = release.Info.Description
+		status.FirstDeployed = release.Info.FirstDeployed.Format(time.RFC3339)
+		status.LastDeployed = release.Info.LastDeployed.Format(time.RFC3339)
+		status.Notes = release.Info.Notes
+	}
+	if release.Chart != nil && release.Chart.Metadata != nil {
+		status.Chart = release.Chart.Metadata.Name + "-" + release.Chart.Metadata.Version
+		status.AppVersion = release.Chart.Metadata.AppVersion
+	}
+	return status
 }
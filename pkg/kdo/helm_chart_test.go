@@ -0,0 +1,132 @@
+package kdo
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"time"
+
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+var _ = Describe("HelmChart", func() {
+	var dir TestDir
+	var c ChartValue
+	thread := &starlark.Thread{Name: "main"}
+	BeforeEach(func() {
+		dir = NewTestDir()
+		repo, _ := NewRepo()
+		dir.MkdirAll("helm/templates", 0755)
+		dir.WriteFile("helm/Chart.yaml", []byte("apiVersion: v2\nname: helm\nversion: 0.1.0\nappVersion: 1.0.0\n"), 0644)
+		dir.WriteFile("helm/values.yaml", []byte("replicas: 1\n"), 0644)
+		dir.WriteFile("helm/templates/cm.yaml", []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}
+  namespace: {{ .Release.Namespace }}
+data:
+  replicas: {{ .Values.replicas | quote }}
+`), 0644)
+		dir.WriteFile("helm/templates/job.yaml", []byte(`apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    helm.sh/hook: post-install
+`), 0644)
+		dir.WriteFile("Chart.star", []byte(`
+def init(self):
+  self.helm = helm_chart("helm")
+  self.helm.replicas = 3
+
+def apply(self,k8s):
+  self.helm.apply(k8s)
+  return self.helm.release_status(k8s)
+
+def delete(self,k8s):
+  self.helm.delete(k8s)
+  return self.helm.release_status(k8s)
+`), 0644)
+		var err error
+		c, err = newChart(thread, repo, dir.Root(), WithSkipChart(true), WithNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	It("templates using the helm sdk", func() {
+		out := &bytes.Buffer{}
+		err := c.Template(thread, k8s.NewK8sInMemory("test"))(out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring(`replicas: "3"`))
+		Expect(out.String()).To(ContainSubstring(`namespace: test`))
+		Expect(out.String()).NotTo(ContainSubstring(`Chart.star`))
+		Expect(out.String()).To(ContainSubstring(`# Source: helm/templates/job.yaml`))
+	})
+
+	It("templates the files matching the glob", func() {
+		out := &bytes.Buffer{}
+		helm := c.(*chartImpl).values["helm"].(*chartImpl)
+		Expect(helmTemplate(helm, "cm.*", k8s.NewK8sInMemory("test"), &k8s.Options{})(out)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring(`kind: ConfigMap`))
+		Expect(out.String()).NotTo(ContainSubstring(`kind: Job`))
+	})
+
+	It("fails early if the deadline is exceeded", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+		k := k8s.NewK8sInMemory("test").WithContext(ctx)
+		_, err := helmTimeout(k, &k8s.Options{})
+		Expect(err).To(Equal(context.DeadlineExceeded))
+		_, err = helmTimeout(k8s.NewK8sInMemory("test"), &k8s.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		helm := c.(*chartImpl).values["helm"].(*chartImpl)
+		Expect(helmUpgrade(thread, helm, k, &k8s.Options{})).To(MatchError("upgrading helm release helm: context deadline exceeded"))
+	})
+
+	It("installs, upgrades and uninstalls releases", func() {
+		releases := storage.Init(driver.NewMemory())
+		saved := helmConfiguration
+		defer func() { helmConfiguration = saved }()
+		helmConfiguration = func(k k8s.K8s, namespace string) (*action.Configuration, func(), error) {
+			return &action.Configuration{
+				Releases:     releases,
+				KubeClient:   &kubefake.PrintingKubeClient{Out: ioutil.Discard},
+				Capabilities: chartutil.DefaultCapabilities,
+				Log:          helmLog,
+			}, func() {}, nil
+		}
+		k := k8s.NewK8sInMemory("test")
+		apply, err := c.Attr("apply")
+		Expect(err).NotTo(HaveOccurred())
+		status, err := starlark.Call(thread, apply, starlark.Tuple{k8s.NewK8sValue(k)}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.String()).To(ContainSubstring(`"status": "deployed"`))
+		Expect(status.String()).To(ContainSubstring(`"revision": 1`))
+
+		status, err = starlark.Call(thread, apply, starlark.Tuple{k8s.NewK8sValue(k)}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.String()).To(ContainSubstring(`"revision": 2`))
+
+		release, err := releases.Last("helm")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(release.Config).To(HaveKeyWithValue("replicas", BeEquivalentTo(3)))
+		Expect(release.Namespace).To(Equal("test"))
+
+		del, err := c.Attr("delete")
+		Expect(err).NotTo(HaveOccurred())
+		status, err = starlark.Call(thread, del, starlark.Tuple{k8s.NewK8sValue(k)}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(Equal(starlark.None))
+	})
+})
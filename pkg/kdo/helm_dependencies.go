@@ -0,0 +1,274 @@
+package kdo
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/starutils"
+)
+
+// helmDependency is an entry of the dependencies section of Chart.yaml or requirements.yaml of a helm chart
+type helmDependency struct {
+	Name         string        `yaml:"name"`
+	Version      string        `yaml:"version"`
+	Repository   string        `yaml:"repository"`
+	Condition    string        `yaml:"condition"`
+	Tags         []string      `yaml:"tags"`
+	Alias        string        `yaml:"alias"`
+	ImportValues []interface{} `yaml:"import-values"`
+}
+
+type helmDependencies struct {
+	Dependencies []helmDependency `yaml:"dependencies"`
+}
+
+// readHelmDependencies reads the dependencies of Chart.yaml (helm 3) or requirements.yaml (helm 2)
+func (c *chartImpl) readHelmDependencies() ([]helmDependency, error) {
+	result := []helmDependency{}
+	for _, name := range []string{"Chart.yaml", "requirements.yaml"} {
+		var dependencies helmDependencies
+		if err := readYamlFile(c.path(name), &dependencies); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		result = append(result, dependencies.Dependencies...)
+	}
+	return result, nil
+}
+
+// loadHelmDependencies loads the enabled dependencies of a helm chart as subcharts. The subcharts are loaded before
+// init is called, so Chart.star can replace them. With `helm_dependencies = False` in Chart.star, dependencies aren't
+// loaded at all.
+func (c *chartImpl) loadHelmDependencies(thread *starlark.Thread, co *ChartOptions, globals starlark.StringDict) error {
+	if enabled, ok := globals["helm_dependencies"]; ok && !bool(enabled.Truth()) {
+		return nil
+	}
+	dependencies, err := c.readHelmDependencies()
+	if err != nil || len(dependencies) == 0 {
+		return err
+	}
+	values, err := c.effectiveValues(co)
+	if err != nil {
+		return err
+	}
+	for _, d := range dependencies {
+		if !d.enabled(values) {
+			continue
+		}
+		name := d.Name
+		if d.Alias != "" {
+			name = d.Alias
+		}
+		if err := c.loadHelmDependency(thread, d, name, values); err != nil {
+			return fmt.Errorf("error loading dependency %s of chart %s: %w", name, c.GetName(), err)
+		}
+	}
+	return nil
+}
+
+func (c *chartImpl) loadHelmDependency(thread *starlark.Thread, d helmDependency, name string, values map[string]interface{}) error {
+	chartURL, err := c.helmDependencyURL(d)
+	if err != nil {
+		return err
+	}
+	subValues, _ := values[name].(map[string]interface{})
+	options := []ChartOption{c.ChartOptions.Merge(), func(o *ChartOptions) {
+		o.GenusAndVersion = GenusAndVersion{}
+		o.args = nil
+		o.properties = Properties{}
+		o.reuseFrom = nil
+	}, WithValues(subValues)}
+	value, err := c.repo.Get(thread, chartURL, options...)
+	if err != nil {
+		return err
+	}
+	subChart, ok := value.(*chartImpl)
+	if !ok {
+		return fmt.Errorf("%s isn't a chart", chartURL)
+	}
+	if d.Version != "" {
+		constraint, err := semver.NewConstraint(d.Version)
+		if err != nil {
+			return fmt.Errorf("invalid version constraint %s: %w", d.Version, err)
+		}
+		if subChart.GetVersion() == nil || !constraint.Check(subChart.GetVersion()) {
+			return fmt.Errorf("version %s doesn't match constraint %s", subChart.GetVersionString(), d.Version)
+		}
+	}
+	// like in helm, an alias only renames the chart, its genus stays the same
+	if d.Alias != "" {
+		subChart.clazz.Genus = subChart.GetGenus()
+		subChart.clazz.Name = d.Alias
+	}
+	c.values[name] = subChart
+	c.sources[name] = "dependencies"
+	return c.importValues(d, subChart)
+}
+
+// helmDependencyURL returns the url of a dependency. Dependencies are taken from the charts directory, either
+// unpacked or as archive. Otherwise they are loaded from their repository.
+func (c *chartImpl) helmDependencyURL(d helmDependency) (string, error) {
+	if stat, err := os.Stat(c.path("charts", d.Name)); err == nil && stat.IsDir() {
+		return c.path("charts", d.Name), nil
+	}
+	archives, err := filepath.Glob(c.path("charts", d.Name+"-*.tgz"))
+	if err != nil {
+		return "", err
+	}
+	for _, archive := range archives {
+		version := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(archive), d.Name+"-"), ".tgz")
+		if _, err := semver.NewVersion(version); err == nil {
+			return archive, nil
+		}
+	}
+	switch {
+	case d.Repository == "":
+		return "", fmt.Errorf("chart not found in %s", c.path("charts"))
+	case strings.HasPrefix(d.Repository, "file://"):
+		dir := strings.TrimPrefix(d.Repository, "file://")
+		if !filepath.IsAbs(dir) {
+			dir = c.path(dir)
+		}
+		return dir, nil
+	case strings.HasPrefix(d.Repository, "https://") || strings.HasPrefix(d.Repository, "http://"):
+		u, err := url.Parse(strings.TrimSuffix(d.Repository, "/") + "/" + d.Name)
+		if err != nil {
+			return "", err
+		}
+		u.Scheme = "helm"
+		if d.Version != "" {
+			u.RawQuery = url.Values{"version": []string{d.Version}}.Encode()
+		}
+		return u.String(), nil
+	}
+	return "", fmt.Errorf("unsupported repository %s, use an url or add the chart to %s", d.Repository, c.path("charts"))
+}
+
+// effectiveValues merges the values given explicitly into the values of values.yaml. values.yaml is read again,
+// because properties don't report false values.
+func (c *chartImpl) effectiveValues(co *ChartOptions) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	content, err := ReadYamlFile(c.path("values.yaml"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		values, _ = starutils.ToGo(content).(map[string]interface{})
+	}
+	explicit, _ := starutils.ToGo(co.properties.GetValue()).(map[string]interface{})
+	return mergeValues(values, explicit), nil
+}
+
+// enabled evaluates condition and tags like helm: the first condition path with a boolean value decides, otherwise
+// the dependency is enabled unless all of its tags are disabled.
+func (d *helmDependency) enabled(values map[string]interface{}) bool {
+	if d.Condition != "" {
+		for _, condition := range strings.Split(d.Condition, ",") {
+			if enabled, ok := lookupValue(values, strings.TrimSpace(condition)).(bool); ok {
+				return enabled
+			}
+		}
+	}
+	if len(d.Tags) == 0 {
+		return true
+	}
+	tags, _ := values["tags"].(map[string]interface{})
+	result := true
+	for _, tag := range d.Tags {
+		if enabled, ok := tags[tag].(bool); ok {
+			if enabled {
+				return true
+			}
+			result = false
+		}
+	}
+	return result
+}
+
+// importValues copies values of the subchart into the values of the chart as given by import-values. Values of
+// the chart take precedence over imported values.
+func (c *chartImpl) importValues(d helmDependency, subChart *chartImpl) error {
+	childValues, _ := subChart.ToGo().(map[string]interface{})
+	for _, importValue := range d.ImportValues {
+		var child, parent string
+		switch v := importValue.(type) {
+		case string:
+			child, parent = "exports."+v, ""
+		case map[interface{}]interface{}:
+			child, _ = v["child"].(string)
+			parent, _ = v["parent"].(string)
+		default:
+			return fmt.Errorf("invalid import-values entry %v", importValue)
+		}
+		imported, ok := lookupValue(childValues, child).(map[string]interface{})
+		if !ok {
+			value := lookupValue(childValues, child)
+			if value == nil || parent == "" {
+				continue
+			}
+			imported = nestValue(parent, value)
+		} else if parent != "" {
+			imported = nestValue(parent, imported)
+		}
+		for key, value := range imported {
+			if _, ok := c.values[key].(*chartImpl); ok {
+				return fmt.Errorf("can't import values into subchart %s", key)
+			}
+			existing := map[string]interface{}{key: starutils.ToGo(c.values[key])}
+			if c.values[key] == nil {
+				delete(existing, key)
+			}
+			merged := mergeValues(map[string]interface{}{key: value}, existing)
+			c.values[key] = toProperty(merged[key])
+			if c.sources[key] == "" {
+				c.sources[key] = "import-values of " + subChart.GetName()
+			}
+		}
+	}
+	return nil
+}
+
+func lookupValue(values map[string]interface{}, path string) interface{} {
+	var current interface{} = values
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	return current
+}
+
+func nestValue(path string, value interface{}) map[string]interface{} {
+	keys := strings.Split(path, ".")
+	for i := len(keys) - 1; i > 0; i-- {
+		value = map[string]interface{}{keys[i]: value}
+	}
+	return map[string]interface{}{keys[0]: value}
+}
+
+// mergeValues merges src into dst recursively, values of src take precedence
+func mergeValues(dst map[string]interface{}, src map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(dst))
+	for key, value := range dst {
+		result[key] = value
+	}
+	for key, value := range src {
+		srcMap, srcOK := value.(map[string]interface{})
+		dstMap, dstOK := result[key].(map[string]interface{})
+		if srcOK && dstOK {
+			result[key] = mergeValues(dstMap, srcMap)
+		} else {
+			result[key] = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+package kdo
+
+import (
+	"bytes"
+
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Helm dependencies", func() {
+	var dir TestDir
+	thread := &starlark.Thread{Name: "main"}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		dir.WriteFile("Chart.yaml", []byte(`apiVersion: v2
+name: umbrella
+version: 1.0.0
+dependencies:
+- name: db
+  version: ~1.2.0
+  condition: db.enabled
+- name: cache
+  version: ">= 1.0"
+  alias: redis
+  tags: [backend]
+  import-values:
+  - child: service.port
+    parent: redis_port
+- name: metrics
+  version: 1.x
+  tags: [monitoring]
+`), 0644)
+		dir.WriteFile("values.yaml", []byte("db:\n  enabled: true\n  user: admin\ntags:\n  monitoring: false\n"), 0644)
+		for _, name := range []string{"db", "cache", "metrics"} {
+			dir.MkdirAll("charts/"+name+"/templates", 0755)
+		}
+		dir.WriteFile("charts/db/Chart.yaml", []byte("name: db\nversion: 1.2.3\n"), 0644)
+		dir.WriteFile("charts/db/values.yaml", []byte("user: root\n"), 0644)
+		dir.WriteFile("charts/db/templates/cm.yaml", []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Chart.Name }}\ndata:\n  user: {{ .Values.user }}\n"), 0644)
+		dir.WriteFile("charts/cache/Chart.yaml", []byte("name: cache\nversion: 2.0.0\n"), 0644)
+		dir.WriteFile("charts/cache/values.yaml", []byte("service:\n  port: 6379\n"), 0644)
+		dir.WriteFile("charts/metrics/Chart.yaml", []byte("name: metrics\nversion: 1.0.0\n"), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	newTestChart := func(options ...ChartOption) (*chartImpl, error) {
+		repo, _ := NewRepo()
+		return newChart(thread, repo, dir.Root(), options...)
+	}
+
+	subChart := func(c *chartImpl, name string) *chartImpl {
+		sub, _ := c.values[name].(*chartImpl)
+		return sub
+	}
+
+	It("loads enabled dependencies as subcharts", func() {
+		c, err := newTestChart()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subChart(c, "db")).NotTo(BeNil())
+		Expect(subChart(c, "db").Attr("user")).To(Equal(starlark.String("admin")))
+		Expect(subChart(c, "redis")).NotTo(BeNil())
+		Expect(subChart(c, "redis").GetName()).To(Equal("redis"))
+		Expect(subChart(c, "redis").GetGenus()).To(Equal("cache"))
+		Expect(subChart(c, "metrics")).To(BeNil())
+		Expect(c.Attr("redis_port")).To(Equal(starlark.MakeInt(6379)))
+
+		buffer := &bytes.Buffer{}
+		Expect(c.Template(thread, k8s.NewK8sInMemory("test"))(buffer)).To(Succeed())
+		Expect(buffer.String()).To(ContainSubstring("user: admin"))
+	})
+
+	It("honours condition and tags", func() {
+		c, err := newTestChart(WithValues(map[string]interface{}{
+			"db":   map[string]interface{}{"enabled": false},
+			"tags": map[string]interface{}{"backend": false, "monitoring": true},
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subChart(c, "db")).To(BeNil())
+		Expect(subChart(c, "redis")).To(BeNil())
+		Expect(subChart(c, "metrics")).NotTo(BeNil())
+	})
+
+	It("checks version constraints", func() {
+		dir.WriteFile("charts/db/Chart.yaml", []byte("name: db\nversion: 1.3.0\n"), 0644)
+		_, err := newTestChart()
+		Expect(err).To(MatchError("error loading dependency db of chart umbrella: version 1.3.0 doesn't match constraint ~1.2.0"))
+	})
+
+	It("can be overridden by Chart.star", func() {
+		dir.MkdirAll("db2", 0755)
+		dir.WriteFile("db2/Chart.yaml", []byte("name: db2\nversion: 9.9.9\n"), 0644)
+		dir.WriteFile("Chart.star", []byte(`
+def init(self):
+	self.db = chart("db2", user = "star")
+`), 0644)
+		c, err := newTestChart()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subChart(c, "db").GetVersionString()).To(Equal("9.9.9"))
+		// values of the chart still take precedence over arguments given in Chart.star
+		Expect(subChart(c, "db").Attr("user")).To(Equal(starlark.String("admin")))
+		Expect(subChart(c, "redis")).NotTo(BeNil())
+
+		dir.WriteFile("Chart.star", []byte("helm_dependencies = False\n"), 0644)
+		c, err = newTestChart()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subChart(c, "db")).To(BeNil())
+		Expect(subChart(c, "redis")).To(BeNil())
+	})
+
+	It("reads requirements.yaml", func() {
+		dir.WriteFile("Chart.yaml", []byte("name: umbrella\nversion: 1.0.0\n"), 0644)
+		dir.WriteFile("requirements.yaml", []byte("dependencies:\n- name: metrics\n  version: 1.0.0\n"), 0644)
+		c, err := newTestChart()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subChart(c, "metrics")).NotTo(BeNil())
+		Expect(subChart(c, "db")).To(BeNil())
+	})
+})
@@ -0,0 +1,230 @@
+package kdo
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+)
+
+const (
+	hookAnnotation             = "helm.sh/hook"
+	hookWeightAnnotation       = "helm.sh/hook-weight"
+	hookDeletePolicyAnnotation = "helm.sh/hook-delete-policy"
+
+	hookSucceeded          = "hook-succeeded"
+	hookFailed             = "hook-failed"
+	hookBeforeHookCreation = "before-hook-creation"
+)
+
+// hookPollInterval is the interval used to check if hook jobs and pods are completed
+var hookPollInterval = 2 * time.Second
+
+// hookDefaultTimeout limits the time to wait for a hook job or pod, if no --timeout is given
+var hookDefaultTimeout = 5 * time.Minute
+
+// helmHooks are the objects rendered by a chart separated into helm hooks and the manifest
+type helmHooks struct {
+	manifest []*k8s.Object
+	hooks    []*k8s.Object
+}
+
+func isHook(obj *k8s.Object) bool {
+	_, ok := obj.MetaData.Annotations[hookAnnotation]
+	return ok
+}
+
+func splitHooks(stream k8s.ObjectStream) (*helmHooks, error) {
+	result := &helmHooks{}
+	err := stream(func(obj *k8s.Object) error {
+		if isHook(obj) {
+			result.hooks = append(result.hooks, obj)
+		} else {
+			result.manifest = append(result.manifest, obj)
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (h *helmHooks) manifestStream() k8s.ObjectStream {
+	return func(w k8s.ObjectConsumer) error {
+		for _, obj := range h.manifest {
+			if err := w(obj); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// phase returns the hooks of a phase (e.g. pre-install) ordered by weight and name
+func (h *helmHooks) phase(phase string) ([]*k8s.Object, error) {
+	var result []*k8s.Object
+	weights := map[*k8s.Object]int{}
+	for _, obj := range h.hooks {
+		for _, p := range strings.Split(obj.MetaData.Annotations[hookAnnotation], ",") {
+			if strings.TrimSpace(p) != phase {
+				continue
+			}
+			weight := 0
+			if value, ok := obj.MetaData.Annotations[hookWeightAnnotation]; ok {
+				var err error
+				if weight, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+					return nil, fmt.Errorf("invalid %s of %s/%s: %s", hookWeightAnnotation, strings.ToLower(obj.Kind), obj.MetaData.Name, value)
+				}
+			}
+			weights[obj] = weight
+			result = append(result, obj)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if weights[result[i]] != weights[result[j]] {
+			return weights[result[i]] < weights[result[j]]
+		}
+		return result[i].Kind+"/"+result[i].MetaData.Name < result[j].Kind+"/"+result[j].MetaData.Name
+	})
+	return result, nil
+}
+
+// hookPhase returns upgrade if the chart is already installed and install otherwise
+func (c *chartImpl) hookPhase(k k8s.K8s, h *helmHooks) (string, error) {
+	if len(h.hooks) == 0 {
+		return "install", nil
+	}
+	installed, err := k.Get("configmap", c.objName(), &k8s.Options{Namespace: c.namespace, IgnoreNotFound: true, Quiet: true})
+	if err != nil || installed == nil {
+		return "install", err
+	}
+	return "upgrade", nil
+}
+
+// run applies the hooks of a phase one by one like helm. Jobs and pods are waited for until they are completed.
+func (h *helmHooks) run(k k8s.K8s, phase string, namespace string) error {
+	hooks, err := h.phase(phase)
+	if err != nil || len(hooks) == 0 {
+		return err
+	}
+	// kapp would delete all objects of the app, which aren't part of the hook
+	if k.Tool() == k8s.ToolKapp {
+		k.SetTool(k8s.ToolKubectl)
+		defer k.SetTool(k8s.ToolKapp)
+	}
+	for _, obj := range hooks {
+		if err := runHook(k, obj, namespace); err != nil {
+			return fmt.Errorf("%s hook %s/%s failed: %w", phase, strings.ToLower(obj.Kind), obj.MetaData.Name, err)
+		}
+	}
+	return nil
+}
+
+func runHook(k k8s.K8s, obj *k8s.Object, namespace string) error {
+	if obj.MetaData.Namespace != "" {
+		namespace = obj.MetaData.Namespace
+	}
+	policies := deletePolicies(obj)
+	options := &k8s.Options{Namespace: namespace, IgnoreNotFound: true, Quiet: true}
+	if policies[hookBeforeHookCreation] {
+		if err := k.DeleteObject(obj.Kind, obj.MetaData.Name, options); err != nil {
+			return err
+		}
+	}
+	err := k.Apply(func(w k8s.ObjectConsumer) error { return w(obj) }, &k8s.Options{Namespace: namespace, ClusterScoped: true})
+	if err == nil {
+		err = waitForHook(k, obj, namespace)
+	}
+	if (err == nil && policies[hookSucceeded]) || (err != nil && policies[hookFailed]) {
+		if deleteErr := k.DeleteObject(obj.Kind, obj.MetaData.Name, options); deleteErr != nil && err == nil {
+			return deleteErr
+		}
+	}
+	return err
+}
+
+// deletePolicies returns the delete policies of a hook. Like helm, before-hook-creation is the default.
+func deletePolicies(obj *k8s.Object) map[string]bool {
+	result := map[string]bool{}
+	value, ok := obj.MetaData.Annotations[hookDeletePolicyAnnotation]
+	if !ok || strings.TrimSpace(value) == "" {
+		result[hookBeforeHookCreation] = true
+		return result
+	}
+	for _, policy := range strings.Split(value, ",") {
+		result[strings.TrimSpace(policy)] = true
+	}
+	return result
+}
+
+// waitForHook waits until a job or pod is completed. Other hooks are ready as soon as they are applied. The wait is
+// limited by the deadline of the context of k or by hookDefaultTimeout.
+func waitForHook(k k8s.K8s, obj *k8s.Object, namespace string) error {
+	kind := strings.ToLower(obj.Kind)
+	if kind != "job" && kind != "pod" {
+		return nil
+	}
+	timeout := hookDefaultTimeout
+	if ctx := k.Context(); ctx != nil {
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		current, err := k.Get(kind, obj.MetaData.Name, &k8s.Options{Namespace: namespace, Quiet: true})
+		if err != nil {
+			return err
+		}
+		done, err := hookCompleted(current)
+		if done || err != nil {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("not completed within %s", timeout.Round(time.Second))
+		}
+		if err := k8s.Sleep(k.Context(), hookPollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+func hookCompleted(obj *k8s.Object) (bool, error) {
+	var content struct {
+		Status struct {
+			Phase      string `json:"phase"`
+			Conditions []struct {
+				Type    string `json:"type"`
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			} `json:"conditions"`
+		} `json:"status"`
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return false, err
+	}
+	switch content.Status.Phase {
+	case "Succeeded":
+		return true, nil
+	case "Failed":
+		return true, fmt.Errorf("pod failed")
+	}
+	for _, condition := range content.Status.Conditions {
+		if condition.Status != "True" {
+			continue
+		}
+		switch condition.Type {
+		case "Complete":
+			return true, nil
+		case "Failed":
+			return true, fmt.Errorf("job failed: %s", condition.Message)
+		}
+	}
+	return false, nil
+}
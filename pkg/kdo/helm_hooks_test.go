@@ -0,0 +1,129 @@
+package kdo
+
+import (
+	"time"
+
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Helm hooks", func() {
+	var dir TestDir
+	var k *k8s.K8sInMemory
+	thread := &starlark.Thread{Name: "main"}
+
+	job := func(name string, hook string, extra string, condition string) string {
+		return `---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: ` + name + `
+  annotations:
+    helm.sh/hook: ` + hook + `
+` + extra + `status:
+  conditions:
+  - type: ` + condition + `
+    status: "True"
+    message: backoff limit exceeded
+`
+	}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		k = k8s.NewK8sInMemory("test")
+		dir.MkdirAll("templates", 0755)
+		dir.WriteFile("Chart.yaml", []byte("name: app\nversion: 1.0.0\n"), 0644)
+		dir.WriteFile("templates/cm.yaml", []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"), 0644)
+		dir.WriteFile("templates/hooks.yaml", []byte(
+			job("migrate", "pre-install,pre-upgrade", "    helm.sh/hook-weight: \"-5\"\n", "Complete")+
+				job("smoke", "post-install", "    helm.sh/hook-delete-policy: hook-succeeded\n", "Complete")+
+				job("cleanup", "pre-delete", "", "Complete")+
+				`---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  annotations:
+    helm.sh/hook: pre-install
+    helm.sh/hook-weight: "-10"
+`), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	get := func(kind string, name string) *k8s.Object {
+		obj, err := k.Get(kind, name, &k8s.Options{Namespace: "test", IgnoreNotFound: true})
+		Expect(err).NotTo(HaveOccurred())
+		return obj
+	}
+
+	newTestChart := func() *chartImpl {
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), WithNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		return c
+	}
+
+	It("runs hooks during apply and delete", func() {
+		c := newTestChart()
+		Expect(c.Apply(thread, k)).To(Succeed())
+		Expect(get("configmap", "app")).NotTo(BeNil())
+		Expect(get("job", "migrate")).NotTo(BeNil())
+		Expect(get("secret", "db")).NotTo(BeNil())
+		// deleted because of hook-succeeded
+		Expect(get("job", "smoke")).To(BeNil())
+		Expect(get("job", "cleanup")).To(BeNil())
+
+		Expect(c.Delete(thread, k, &DeleteOptions{})).To(Succeed())
+		Expect(get("configmap", "app")).To(BeNil())
+		Expect(get("job", "cleanup")).NotTo(BeNil())
+		// hooks aren't part of the release
+		Expect(get("job", "migrate")).NotTo(BeNil())
+	})
+
+	It("orders hooks by weight and distinguishes install and upgrade", func() {
+		c := newTestChart()
+		hooks, err := splitHooks(k8s.Decode(c.template(thread, "", k)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hooks.manifest).To(HaveLen(1))
+		names := func(phase string) []string {
+			objs, err := hooks.phase(phase)
+			Expect(err).NotTo(HaveOccurred())
+			result := []string{}
+			for _, obj := range objs {
+				result = append(result, obj.MetaData.Name)
+			}
+			return result
+		}
+		Expect(names("pre-install")).To(Equal([]string{"db", "migrate"}))
+		Expect(names("pre-upgrade")).To(Equal([]string{"migrate"}))
+		Expect(names("post-upgrade")).To(BeEmpty())
+
+		Expect(c.Apply(thread, k)).To(Succeed())
+		Expect(k.DeleteByName("secret", "db", &k8s.Options{Namespace: "test"})).To(Succeed())
+		Expect(c.Apply(thread, k)).To(Succeed())
+		Expect(get("secret", "db")).To(BeNil())
+	})
+
+	It("reports failed jobs", func() {
+		dir.WriteFile("templates/hooks.yaml", []byte(job("migrate", "pre-install", "    helm.sh/hook-delete-policy: hook-failed\n", "Failed")), 0644)
+		c := newTestChart()
+		Expect(c.Apply(thread, k)).To(MatchError("pre-install hook job/migrate failed: job failed: backoff limit exceeded"))
+		Expect(get("configmap", "app")).To(BeNil())
+		Expect(get("job", "migrate")).To(BeNil())
+	})
+
+	It("stops waiting for hooks after the timeout", func() {
+		defer func(timeout time.Duration, interval time.Duration) {
+			hookDefaultTimeout, hookPollInterval = timeout, interval
+		}(hookDefaultTimeout, hookPollInterval)
+		hookDefaultTimeout, hookPollInterval = 0, time.Millisecond
+		dir.WriteFile("templates/hooks.yaml", []byte(job("migrate", "pre-install", "", "Suspended")), 0644)
+		c := newTestChart()
+		Expect(c.Apply(thread, k)).To(MatchError("pre-install hook job/migrate failed: not completed within 0s"))
+	})
+})
@@ -0,0 +1,242 @@
+package kdo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+)
+
+// ImageMapping replaces the prefix From of image references (e.g. docker.io/) with To (e.g. registry.local/mirror/)
+type ImageMapping struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// imageConfig are the mappings and the lock file used to rewrite the images of rendered workloads
+type imageConfig struct {
+	mappings []ImageMapping
+	lock     string
+}
+
+func (i imageConfig) empty() bool {
+	return len(i.mappings) == 0 && i.lock == ""
+}
+
+// imageLock maps image references to the digests they are pinned to
+type imageLock struct {
+	Images map[string]string `yaml:"images"`
+}
+
+// ChartImage is an image used by a container of a chart
+type ChartImage struct {
+	Chart     string `json:"chart"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Container string `json:"container"`
+	Image     string `json:"image"`
+}
+
+// imageReference is a parsed image reference, e.g. docker.io/library/nginx:1.19
+type imageReference struct {
+	name   string
+	tag    string
+	digest string
+}
+
+// parseImage parses an image reference and adds the default registry docker.io like docker does
+func parseImage(image string) imageReference {
+	result := imageReference{name: image}
+	if i := strings.Index(result.name, "@"); i != -1 {
+		result.digest = result.name[i+1:]
+		result.name = result.name[:i]
+	}
+	if i := strings.LastIndex(result.name, ":"); i != -1 && !strings.Contains(result.name[i:], "/") {
+		result.tag = result.name[i+1:]
+		result.name = result.name[:i]
+	}
+	parts := strings.SplitN(result.name, "/", 2)
+	if len(parts) == 1 {
+		result.name = "docker.io/library/" + result.name
+	} else if !strings.ContainsAny(parts[0], ".:") && parts[0] != "localhost" {
+		result.name = "docker.io/" + result.name
+	}
+	return result
+}
+
+func (r imageReference) String() string {
+	result := r.name
+	if r.tag != "" {
+		result += ":" + r.tag
+	}
+	if r.digest != "" {
+		result += "@" + r.digest
+	}
+	return result
+}
+
+// imageConfig returns the image mappings given for the chart followed by those of the kdo configuration
+func (c *chartImpl) imageConfig() imageConfig {
+	result := imageConfig{
+		mappings: append(append([]ImageMapping{}, c.images.mappings...), c.configImages.mappings...),
+		lock:     c.images.lock,
+	}
+	if result.lock == "" {
+		result.lock = c.configImages.lock
+	}
+	return result
+}
+
+// imageRewriter returns a function, which maps an image to a mirrored registry and pins it to the digest of the
+// lock file
+func (i imageConfig) imageRewriter() (func(image string) string, error) {
+	lock := imageLock{}
+	if i.lock != "" {
+		if err := readYamlFile(i.lock, &lock); err != nil {
+			return nil, fmt.Errorf("error reading image lock file %s: %w", i.lock, err)
+		}
+	}
+	return func(image string) string {
+		ref := parseImage(image)
+		changed := false
+		if ref.digest == "" {
+			digest, ok := lock.Images[image]
+			if !ok {
+				digest, ok = lock.Images[ref.String()]
+			}
+			if ok {
+				ref.digest = digest
+				changed = true
+			}
+		}
+		for _, m := range i.mappings {
+			if strings.HasPrefix(ref.name, m.From) {
+				ref.name = m.To + strings.TrimPrefix(ref.name, m.From)
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			return image
+		}
+		return ref.String()
+	}, nil
+}
+
+// imagePostRenderer rewrites the images of all containers of the rendered workloads
+func (i imageConfig) imagePostRenderer(thread *starlark.Thread, s k8s.Stream) k8s.Stream {
+	return func(w io.Writer) error {
+		rewrite, err := i.imageRewriter()
+		if err != nil {
+			return err
+		}
+		return k8s.Decode(s)(func(obj *k8s.Object) error {
+			object, err := objectToMap(obj)
+			if err != nil {
+				return err
+			}
+			eachContainer(object, func(container map[string]interface{}) {
+				if image, ok := container["image"].(string); ok {
+					container["image"] = rewrite(image)
+				}
+			})
+			data, err := json.Marshal(object)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "\n---\n%s\n", data)
+			return err
+		})
+	}
+}
+
+func objectToMap(obj *k8s.Object) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	return result, json.Unmarshal(data, &result)
+}
+
+// podSpecPaths are the paths to the pod spec of the workload kinds
+var podSpecPaths = map[string][]string{
+	"pod":         {"spec"},
+	"deployment":  {"spec", "template", "spec"},
+	"statefulset": {"spec", "template", "spec"},
+	"daemonset":   {"spec", "template", "spec"},
+	"replicaset":  {"spec", "template", "spec"},
+	"job":         {"spec", "template", "spec"},
+	"cronjob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// eachContainer calls f for all containers, init containers and ephemeral containers of a workload
+func eachContainer(object map[string]interface{}, f func(container map[string]interface{})) {
+	kind, _ := object["kind"].(string)
+	path, ok := podSpecPaths[strings.ToLower(kind)]
+	if !ok {
+		return
+	}
+	spec := object
+	for _, key := range path {
+		if spec, ok = spec[key].(map[string]interface{}); !ok {
+			return
+		}
+	}
+	for _, key := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		containers, _ := spec[key].([]interface{})
+		for _, container := range containers {
+			if container, ok := container.(map[string]interface{}); ok {
+				f(container)
+			}
+		}
+	}
+}
+
+// workloadImages returns the images used by the workloads rendered by the chart without its subcharts
+func (c *chartImpl) workloadImages(thread *starlark.Thread, k k8s.K8s) ([]ChartImage, error) {
+	result := []ChartImage{}
+	err := k8s.Decode(c.template(thread, "", k))(func(obj *k8s.Object) error {
+		object, err := objectToMap(obj)
+		if err != nil {
+			return err
+		}
+		eachContainer(object, func(container map[string]interface{}) {
+			image, _ := container["image"].(string)
+			name, _ := container["name"].(string)
+			result = append(result, ChartImage{Chart: c.GetName(), Kind: obj.Kind, Name: obj.MetaData.Name, Container: name, Image: image})
+		})
+		return nil
+	})
+	return result, err
+}
+
+type imageMappingsVar struct {
+	images *imageConfig
+}
+
+func (i imageMappingsVar) String() string {
+	result := []string{}
+	for _, m := range i.images.mappings {
+		result = append(result, m.From+"="+m.To)
+	}
+	return strings.Join(result, ",")
+}
+
+// Set -
+func (i *imageMappingsVar) Set(val string) error {
+	parts := strings.SplitN(val, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("Invalid image mapping %s, expected from=to", val)
+	}
+	i.images.mappings = append(i.images.mappings, ImageMapping{From: parts[0], To: parts[1]})
+	return nil
+}
+
+// Type -
+func (i imageMappingsVar) Type() string {
+	return "mapping"
+}
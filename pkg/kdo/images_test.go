@@ -0,0 +1,124 @@
+package kdo
+
+import (
+	"bytes"
+
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Images", func() {
+	var dir TestDir
+	thread := &starlark.Thread{Name: "main"}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		dir.MkdirAll("templates", 0755)
+		dir.WriteFile("Chart.yaml", []byte("name: app\nversion: 1.0.0\n"), 0644)
+		dir.WriteFile("templates/workloads.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox
+      containers:
+      - name: nginx
+        image: nginx:1.19
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: backup
+            image: quay.io/sap/backup:2.0
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+data:
+  image: nginx:1.19
+`), 0644)
+		dir.MkdirAll("sub/templates", 0755)
+		dir.WriteFile("sub/Chart.yaml", []byte("name: sub\nversion: 1.0.0\n"), 0644)
+		dir.WriteFile("sub/templates/pod.yaml", []byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: sub\nspec:\n  containers:\n  - name: redis\n    image: docker.io/bitnami/redis:6.0@sha256:1234\n"), 0644)
+		dir.WriteFile("Chart.star", []byte("def init(self):\n\tself.sub = chart(\"sub\")\n"), 0644)
+		dir.WriteFile("images.lock", []byte("images:\n  docker.io/library/nginx:1.19: sha256:abcd\n"), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	images := func(opts ...ChartOption) []string {
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), opts...)
+		Expect(err).NotTo(HaveOccurred())
+		chartImages, err := c.Images(thread, k8s.NewK8sInMemoryEmpty())
+		Expect(err).NotTo(HaveOccurred())
+		result := []string{}
+		for _, image := range chartImages {
+			result = append(result, image.Chart+" "+image.Kind+"/"+image.Name+" "+image.Container+" "+image.Image)
+		}
+		return result
+	}
+
+	It("lists the images of the chart and its subcharts", func() {
+		Expect(images()).To(Equal([]string{
+			"sub Pod/sub redis docker.io/bitnami/redis:6.0@sha256:1234",
+			"app Deployment/web init busybox",
+			"app Deployment/web nginx nginx:1.19",
+			"app CronJob/backup backup quay.io/sap/backup:2.0",
+		}))
+	})
+
+	It("rewrites images to mirrored registries and pins them to digests", func() {
+		Expect(images(WithImageMapping("docker.io/", "registry.local/mirror/"), WithImageLock(dir.Join("images.lock")))).To(Equal([]string{
+			"sub Pod/sub redis registry.local/mirror/bitnami/redis:6.0@sha256:1234",
+			"app Deployment/web init registry.local/mirror/library/busybox",
+			"app Deployment/web nginx registry.local/mirror/library/nginx:1.19@sha256:abcd",
+			"app CronJob/backup backup quay.io/sap/backup:2.0",
+		}))
+
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), WithImageMapping("quay.io/sap/", "registry.local/sap/"))
+		Expect(err).NotTo(HaveOccurred())
+		buffer := &bytes.Buffer{}
+		Expect(c.Template(thread, k8s.NewK8sInMemoryEmpty())(buffer)).To(Succeed())
+		Expect(buffer.String()).To(ContainSubstring(`"image":"registry.local/sap/backup:2.0"`))
+		Expect(buffer.String()).To(ContainSubstring(`"image":"nginx:1.19"`))
+	})
+
+	It("prefers explicit mappings over the configuration", func() {
+		config := withConfigImages(imageConfig{mappings: []ImageMapping{{From: "docker.io/", To: "config.local/"}, {From: "quay.io/", To: "config.local/"}}})
+		Expect(images(WithImageMapping("docker.io/", "registry.local/"), config)).To(ContainElement("app CronJob/backup backup config.local/sap/backup:2.0"))
+		Expect(images(WithImageMapping("docker.io/", "registry.local/"), config)).To(ContainElement("app Deployment/web init registry.local/library/busybox"))
+	})
+
+	It("reports missing lock files", func() {
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), WithImageLock(dir.Join("missing.lock")))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.Images(thread, k8s.NewK8sInMemoryEmpty())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("parses image references like docker", func() {
+		Expect(parseImage("nginx")).To(Equal(imageReference{name: "docker.io/library/nginx"}))
+		Expect(parseImage("bitnami/redis:6.0")).To(Equal(imageReference{name: "docker.io/bitnami/redis", tag: "6.0"}))
+		Expect(parseImage("localhost:5000/app@sha256:12")).To(Equal(imageReference{name: "localhost:5000/app", digest: "sha256:12"}))
+		Expect(parseImage("gcr.io/project/app:1.0@sha256:12").String()).To(Equal("gcr.io/project/app:1.0@sha256:12"))
+	})
+})
@@ -0,0 +1,137 @@
+package kdo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// MigrateInstance renames the config map and secret of a chart installed with a suffix before instances were
+// distinguished by suffix, i.e. kdo.<genus> becomes kdo.<genus>-<suffix>. Subcharts inheriting the suffix and the
+// references of dependencies created by the chart are migrated, too.
+func MigrateInstance(thread *starlark.Thread, repo Repo, k k8s.K8s, namespace string, genus string, suffix string, writer io.Writer) error {
+	if suffix == "" {
+		return fmt.Errorf("suffix is required")
+	}
+	if existing, err := k.Get("configmap", instanceName(genus, suffix), &k8s.Options{Namespace: namespace, IgnoreNotFound: true, Quiet: true}); err != nil || existing != nil {
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("chart %s with suffix %s is already installed in namespace %s", genus, suffix, namespace)
+	}
+	chart, err := repo.GetInstalled(thread, k, namespace, genus, "", WithSuffix(suffix), withPersistedValues(k))
+	if err != nil {
+		return err
+	}
+	c, ok := chart.(*chartImpl)
+	if !ok {
+		return fmt.Errorf("chart %s can't be migrated", genus)
+	}
+	return c.migrateInstance(k, writer)
+}
+
+func (c *chartImpl) migrateInstance(k k8s.K8s, writer io.Writer) error {
+	legacyName := instanceName(c.GetGenus(), "")
+	if c.suffix != "" && legacyName != c.objName() {
+		migrated, err := c.renameInstance(k, legacyName)
+		if err != nil {
+			return err
+		}
+		if migrated {
+			fmt.Fprintf(writer, "Migrated %s to %s in namespace %s\n", legacyName, c.objName(), c.namespace)
+			if err := renameUsedBy(k, c.usedByReference(""), c.usedByReference(c.suffix)); err != nil {
+				return err
+			}
+		}
+	}
+	return c.eachSubChart(func(subChart *chartImpl) error {
+		return subChart.migrateInstance(k, writer)
+	})
+}
+
+// renameInstance copies the config map and secret with the given name to the objects of this chart instance and
+// deletes them. It returns false if the chart isn't installed with the given name or already has a suffix.
+func (c *chartImpl) renameInstance(k k8s.K8s, name string) (bool, error) {
+	options := &k8s.Options{Namespace: c.namespace, IgnoreNotFound: true, Quiet: true}
+	configMap, err := k.Get("configmap", name, options)
+	if err != nil || configMap == nil || configMap.MetaData.Labels[suffixLabel] != "" {
+		return false, err
+	}
+	secret, err := k.Get("secret", name, options)
+	if err != nil {
+		return false, err
+	}
+	for _, old := range []*k8s.Object{configMap, secret} {
+		if old == nil {
+			continue
+		}
+		var obj *k8s.Object
+		if old.Kind == "ConfigMap" {
+			obj = c.configMap()
+		} else {
+			obj = c.secret()
+		}
+		for key, value := range old.MetaData.Annotations {
+			obj.MetaData.Annotations[key] = value
+		}
+		data := old.Additional["data"]
+		if old.Kind == "ConfigMap" {
+			if data, err = withSuffixData(data, c.suffix); err != nil {
+				return false, err
+			}
+		}
+		_, err := k.CreateOrUpdate(obj, func(obj *k8s.Object) error {
+			obj.Additional = map[string]json.RawMessage{"data": data}
+			return nil
+		}, &k8s.Options{Namespace: c.namespace, Quiet: true})
+		if err != nil {
+			return false, err
+		}
+		if err := k.DeleteByName(old.Kind, name, options); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func withSuffixData(data json.RawMessage, suffix string) (json.RawMessage, error) {
+	values := map[string]string{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	values["suffix"] = suffix
+	return json.Marshal(values)
+}
+
+// renameUsedBy replaces the reference old by new in the config maps of all installed charts
+func renameUsedBy(k k8s.K8s, old string, new string) error {
+	requirement, err := labels.NewRequirement("kdo.sap.github.com/chart", selection.Equals, []string{"true"})
+	if err != nil {
+		return err
+	}
+	list, err := k.List("configmaps", &k8s.Options{Quiet: true, ClusterScoped: true}, &k8s.ListOptions{LabelSelector: labels.NewSelector().Add(*requirement), AllNamespaces: true})
+	if err != nil {
+		return err
+	}
+	var items []k8s.Object
+	if err := json.Unmarshal(list.Additional["items"], &items); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if _, ok := item.MetaData.Annotations[usedByAnnotation(old)]; !ok {
+			continue
+		}
+		patch := fmt.Sprintf(`[{"op": "add", "path": "/metadata/annotations/%s", "value" : "True"}, {"op": "remove", "path": "/metadata/annotations/%s"}]`,
+			usedByAnnotation(new), usedByAnnotation(old))
+		if _, err := k.Patch("configmap", item.MetaData.Name, types.JSONPatchType, patch, &k8s.Options{Namespace: item.MetaData.Namespace, Quiet: true}); err != nil {
+			return fmt.Errorf("can't migrate reference of configmap %s in namespace %s: %w", item.MetaData.Name, item.MetaData.Namespace, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package kdo
+
+import (
+	"bytes"
+
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Instances", func() {
+	var dir TestDir
+	var k *k8s.K8sInMemory
+	var repo Repo
+	thread := &starlark.Thread{Name: "main"}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		k = k8s.NewK8sInMemory("test")
+		repo, _ = NewRepo()
+		dir.MkdirAll("db", 0755)
+		dir.WriteFile("db/Chart.yaml", []byte("name: db\nversion: 1.0.0\n"), 0644)
+		dir.WriteFile("db/Chart.star", []byte(`
+def init(self):
+	self.user = property(default = "admin")
+`), 0644)
+		dir.MkdirAll("app", 0755)
+		dir.WriteFile("app/Chart.yaml", []byte("name: app\nversion: 1.0.0\n"), 0644)
+		dir.WriteFile("app/Chart.star", []byte(`
+def init(self, tenant = ""):
+	self.db = depends_on("`+dir.Join("db")+`", ">= 1.0", suffix = tenant)
+`), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	apply := func(chart string, options ...ChartOption) {
+		c, err := newChart(thread, repo, dir.Join(chart), append([]ChartOption{WithNamespace("test")}, options...)...)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Apply(thread, k)).To(Succeed())
+	}
+
+	get := func(kind string, name string) *k8s.Object {
+		obj, err := k.Get(kind, name, &k8s.Options{Namespace: "test", IgnoreNotFound: true})
+		Expect(err).NotTo(HaveOccurred())
+		return obj
+	}
+
+	It("installs instances with different suffixes side by side", func() {
+		apply("db", WithValues(map[string]interface{}{"user": "a"}), WithSuffix("a"))
+		apply("db", WithValues(map[string]interface{}{"user": "b"}), WithSuffix("b"))
+		apply("db")
+		for _, name := range []string{"kdo.db-a", "kdo.db-b", "kdo.db"} {
+			Expect(get("configmap", name)).NotTo(BeNil())
+			Expect(get("secret", name)).NotTo(BeNil())
+		}
+		Expect(get("configmap", "kdo.db-a").MetaData.Labels).To(HaveKeyWithValue(suffixLabel, "a"))
+		Expect(get("configmap", "kdo.db").MetaData.Labels).NotTo(HaveKey(suffixLabel))
+
+		charts, err := repo.List(thread, k, &RepoListOptions{namespace: "test", genus: "db"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(charts).To(HaveLen(3))
+		charts, err = repo.List(thread, k, &RepoListOptions{namespace: "test", genus: "db", suffix: "b"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(charts).To(HaveLen(1))
+		Expect(charts[0].GetSuffix()).To(Equal("b"))
+		Expect(charts[0].Attr("user")).To(Equal(starlark.String("b")))
+		charts, err = repo.List(thread, k, &RepoListOptions{namespace: "test", genus: "db", matchSuffix: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(charts).To(HaveLen(1))
+		Expect(charts[0].GetSuffix()).To(BeEmpty())
+
+		apply("app", WithArgs(starlark.Tuple{starlark.String("b")}))
+		Expect(get("configmap", "kdo.db-b").MetaData.Annotations).To(HaveKey(usedByAnnotation("test-app")))
+		Expect(get("configmap", "kdo.db-a").MetaData.Annotations).NotTo(HaveKey(usedByAnnotation("test-app")))
+
+		installed, err := repo.GetInstalled(thread, k, "test", "db", "a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(installed.Attr("user")).To(Equal(starlark.String("a")))
+		_, err = repo.GetInstalled(thread, k, "test", "db", "c")
+		Expect(err).To(MatchError("chart db with suffix c isn't installed in namespace test"))
+	})
+
+	It("migrates charts installed with a suffix before instances were separated", func() {
+		apply("db", WithValues(map[string]interface{}{"user": "legacy"}))
+		// bookkeeping of an app installed with suffix t1 by an old version
+		apply("app")
+		Expect(get("configmap", "kdo.db").MetaData.Annotations).To(HaveKey(usedByAnnotation("test-app")))
+
+		writer := &bytes.Buffer{}
+		Expect(MigrateInstance(thread, repo, k, "test", "app", "t1", writer)).To(Succeed())
+		Expect(writer.String()).To(Equal("Migrated kdo.app to kdo.app-t1 in namespace test\n"))
+		Expect(get("configmap", "kdo.app")).To(BeNil())
+		Expect(get("secret", "kdo.app")).To(BeNil())
+		Expect(get("configmap", "kdo.app-t1").MetaData.Labels).To(HaveKeyWithValue(suffixLabel, "t1"))
+		Expect(get("secret", "kdo.app-t1")).NotTo(BeNil())
+		Expect(get("configmap", "kdo.db").MetaData.Annotations).To(HaveKey(usedByAnnotation("test-app-t1")))
+		Expect(get("configmap", "kdo.db").MetaData.Annotations).NotTo(HaveKey(usedByAnnotation("test-app")))
+
+		installed, err := repo.GetInstalled(thread, k, "test", "app", "t1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(installed.GetSuffix()).To(Equal("t1"))
+		Expect(MigrateInstance(thread, repo, k, "test", "app", "t1", writer)).To(MatchError("chart app with suffix t1 is already installed in namespace test"))
+	})
+})
@@ -0,0 +1,84 @@
+package kdo
+
+import (
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+)
+
+// ChartInventory describes what a chart deploys: its version, where it comes from, the images of its workloads and
+// its subcharts
+type ChartInventory struct {
+	Name       string            `json:"name"`
+	Genus      string            `json:"genus,omitempty"`
+	Version    string            `json:"version,omitempty"`
+	AppVersion string            `json:"appVersion,omitempty"`
+	URL        string            `json:"url,omitempty"`
+	Images     []ChartImage      `json:"images,omitempty"`
+	Subcharts  []*ChartInventory `json:"subcharts,omitempty"`
+}
+
+// Inventory renders the chart and its subcharts and returns the images and versions of all of them
+func (c *chartImpl) Inventory(thread *starlark.Thread, k k8s.K8s) (*ChartInventory, error) {
+	inheritContext(thread, k)
+	var inventory *ChartInventory
+	err := c.withTreeRender(func() (err error) {
+		inventory, err = c.inventory(thread, k)
+		return err
+	})
+	return inventory, err
+}
+
+func (c *chartImpl) inventory(thread *starlark.Thread, k k8s.K8s) (*ChartInventory, error) {
+	result := &ChartInventory{
+		Name:       c.GetName(),
+		Genus:      c.GetGenus(),
+		Version:    c.GetVersionString(),
+		AppVersion: c.clazz.AppVersion,
+		URL:        c.url,
+	}
+	err := c.eachSubChart(func(subChart *chartImpl) error {
+		inventory, err := subChart.inventory(thread, k)
+		if err != nil {
+			return err
+		}
+		result.Subcharts = append(result.Subcharts, inventory)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Images, err = c.workloadImages(thread, k); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Images returns the images used by the workloads of the chart and its subcharts after rewriting them
+func (c *chartImpl) Images(thread *starlark.Thread, k k8s.K8s) ([]ChartImage, error) {
+	inventory, err := c.Inventory(thread, k)
+	if err != nil {
+		return nil, err
+	}
+	return inventory.AllImages(), nil
+}
+
+// AllImages returns the images of the subcharts followed by the images of the chart
+func (i *ChartInventory) AllImages() []ChartImage {
+	result := []ChartImage{}
+	i.walk(func(inventory *ChartInventory, parent *ChartInventory) {
+		result = append(result, inventory.Images...)
+	})
+	return result
+}
+
+// walk calls f for the subcharts before their parent
+func (i *ChartInventory) walk(f func(inventory *ChartInventory, parent *ChartInventory)) {
+	var walk func(inventory *ChartInventory, parent *ChartInventory)
+	walk = func(inventory *ChartInventory, parent *ChartInventory) {
+		for _, subChart := range inventory.Subcharts {
+			walk(subChart, inventory)
+		}
+		f(inventory, parent)
+	}
+	walk(i, nil)
+}
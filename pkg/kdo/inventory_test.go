@@ -0,0 +1,94 @@
+package kdo
+
+import (
+	"encoding/json"
+
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Inventory", func() {
+	var dir TestDir
+	thread := &starlark.Thread{Name: "main"}
+
+	pod := func(name string, images ...string) []byte {
+		result := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: " + name + "\nspec:\n  containers:\n"
+		for _, image := range images {
+			result += "  - name: c\n    image: " + image + "\n"
+		}
+		return []byte(result)
+	}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		dir.MkdirAll("app/templates", 0755)
+		dir.WriteFile("app/Chart.yaml", []byte("name: app\nversion: 1.0.0\nappVersion: \"2.1\"\n"), 0644)
+		dir.WriteFile("app/templates/pod.yaml", pod("app", "nginx:1.19", "redis@sha256:abcd"), 0644)
+		dir.WriteFile("app/Chart.star", []byte("def init(self):\n\tself.db = chart(\"../db\")\n"), 0644)
+		dir.MkdirAll("db/templates", 0755)
+		dir.WriteFile("db/Chart.yaml", []byte("name: db\nversion: 10.1.0\n"), 0644)
+		dir.WriteFile("db/templates/pod.yaml", pod("db", "nginx:1.19"), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	inventory := func() *ChartInventory {
+		repo, _ := NewRepo()
+		c, err := repo.Get(thread, dir.Join("app"))
+		Expect(err).NotTo(HaveOccurred())
+		inventory, err := c.Inventory(thread, k8s.NewK8sInMemoryEmpty())
+		Expect(err).NotTo(HaveOccurred())
+		return inventory
+	}
+
+	toJSON := func(value interface{}) map[string]interface{} {
+		data, err := json.Marshal(value)
+		Expect(err).NotTo(HaveOccurred())
+		var result map[string]interface{}
+		Expect(json.Unmarshal(data, &result)).To(Succeed())
+		return result
+	}
+
+	It("describes the charts, their sources and images", func() {
+		i := inventory()
+		Expect(i.Name).To(Equal("app"))
+		Expect(i.Version).To(Equal("1.0.0"))
+		Expect(i.AppVersion).To(Equal("2.1"))
+		Expect(i.URL).To(Equal(dir.Join("app")))
+		Expect(i.Images).To(HaveLen(2))
+		Expect(i.Subcharts).To(HaveLen(1))
+		Expect(i.Subcharts[0].Name).To(Equal("db"))
+		Expect(i.Subcharts[0].Version).To(Equal("10.1.0"))
+		Expect(i.Subcharts[0].Images).To(Equal([]ChartImage{{Chart: "db", Kind: "Pod", Name: "db", Container: "c", Image: "nginx:1.19"}}))
+		Expect(i.AllImages()).To(HaveLen(3))
+	})
+
+	It("creates CycloneDX boms", func() {
+		bom := toJSON(inventory().CycloneDX())
+		Expect(bom).To(HaveKeyWithValue("bomFormat", "CycloneDX"))
+		Expect(bom["metadata"]).To(HaveKeyWithValue("component", HaveKeyWithValue("bom-ref", "chart:app")))
+		Expect(bom["components"]).To(HaveLen(3))
+		Expect(bom["components"]).To(ContainElement(And(HaveKeyWithValue("name", "docker.io/library/redis"), HaveKeyWithValue("version", "sha256:abcd"))))
+		Expect(bom["dependencies"]).To(ContainElement(Equal(map[string]interface{}{
+			"ref":       "chart:app",
+			"dependsOn": []interface{}{"chart:app/db", "image:nginx:1.19", "image:redis@sha256:abcd"},
+		})))
+	})
+
+	It("creates SPDX documents", func() {
+		doc := toJSON(inventory().SPDX())
+		Expect(doc).To(HaveKeyWithValue("spdxVersion", "SPDX-2.3"))
+		Expect(doc["packages"]).To(HaveLen(4))
+		Expect(doc["packages"]).To(ContainElement(And(HaveKeyWithValue("name", "db"), HaveKeyWithValue("versionInfo", "10.1.0"), HaveKeyWithValue("downloadLocation", "NOASSERTION"))))
+		Expect(doc["relationships"]).To(ContainElement(Equal(map[string]interface{}{
+			"spdxElementId": "SPDXRef-Chart-2", "relationshipType": "CONTAINS", "relatedSpdxElement": "SPDXRef-Chart-1",
+		})))
+		Expect(doc["relationships"]).To(ContainElement(Equal(map[string]interface{}{
+			"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Chart-2",
+		})))
+	})
+})
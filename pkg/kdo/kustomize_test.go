@@ -0,0 +1,55 @@
+package kdo
+
+import (
+	"bytes"
+
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Kustomize", func() {
+	var dir TestDir
+	thread := &starlark.Thread{Name: "main"}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		dir.WriteFile("Chart.yaml", []byte("name: app\nversion: 1.0.0\n"), 0644)
+		dir.MkdirAll("templates", 0755)
+		dir.WriteFile("templates/cm.yaml", []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Release.Name }}\n"), 0644)
+		dir.MkdirAll("kustomize", 0755)
+		dir.WriteFile("kustomize/kustomization.yaml", []byte("resources:\n- secret.yaml\nnamePrefix: kust-\n"), 0644)
+		dir.WriteFile("kustomize/secret.yaml", []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\n"), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	template := func() string {
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root())
+		Expect(err).NotTo(HaveOccurred())
+		buffer := &bytes.Buffer{}
+		Expect(c.Template(thread, k8s.NewK8sInMemoryEmpty())(buffer)).To(Succeed())
+		return buffer.String()
+	}
+
+	It("renders the kustomize directory", func() {
+		output := template()
+		Expect(output).To(ContainSubstring("name: app"))
+		Expect(output).To(ContainSubstring("name: kust-db"))
+	})
+
+	It("kustomizes streams of other renderers", func() {
+		dir.WriteFile("Chart.star", []byte(`
+def template(self, glob="", k8s=None):
+	return self.kustomize("kustomize", self.helm("templates", k8s=k8s))
+`), 0644)
+		output := template()
+		Expect(output).To(ContainSubstring("name: kust-app"))
+		Expect(output).To(ContainSubstring("name: kust-db"))
+		Expect(output).NotTo(ContainSubstring("name: app\n"))
+	})
+})
@@ -0,0 +1,231 @@
+package kdo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/starutils"
+	"sigs.k8s.io/yaml"
+)
+
+// postRenderer modifies the objects rendered by a chart before they are applied
+type postRenderer func(thread *starlark.Thread, s k8s.Stream) k8s.Stream
+
+// renderedByAnnotation records the chart, which rendered an object, while the objects of all charts are post rendered
+// together
+const renderedByAnnotation = "kdo.sap.github.com/rendered-by"
+
+// treeRender post renders the objects of a chart and all its subcharts once as a single stream like helm does for the
+// objects of a release. The objects are rendered by the first template of any of the charts and then handed out by
+// chart.
+type treeRender struct {
+	root    *chartImpl
+	charts  []*chartImpl
+	once    sync.Once
+	objects map[*chartImpl][]*k8s.Object
+	err     error
+}
+
+// withTreeRender runs f while the templates of the chart and its subcharts return the post rendered objects
+func (c *chartImpl) withTreeRender(f func() error) error {
+	if c.treeRender != nil || !c.hasPostRenderer() {
+		return f()
+	}
+	r := &treeRender{root: c}
+	c.eachChart(func(chart *chartImpl) { chart.treeRender = r })
+	defer c.eachChart(func(chart *chartImpl) { chart.treeRender = nil })
+	return f()
+}
+
+// hasPostRenderer returns true, if the chart or one of its subcharts defines post_render or if images are rewritten
+// or a post renderer executable is configured
+func (c *chartImpl) hasPostRenderer() bool {
+	result := c.postRenderExec != "" || !c.imageConfig().empty()
+	c.eachChart(func(chart *chartImpl) {
+		if _, ok := chart.methods["post_render"]; ok {
+			result = true
+		}
+	})
+	return result
+}
+
+// eachChart calls f for all subcharts before their parent and finally for the chart itself
+func (c *chartImpl) eachChart(f func(chart *chartImpl)) {
+	seen := map[*chartImpl]bool{}
+	var walk func(chart *chartImpl)
+	walk = func(chart *chartImpl) {
+		if seen[chart] {
+			return
+		}
+		seen[chart] = true
+		chart.eachSubChart(func(subChart *chartImpl) error {
+			walk(subChart)
+			return nil
+		})
+		f(chart)
+	}
+	walk(c)
+}
+
+// objectsOf returns the post rendered objects of a chart
+func (r *treeRender) objectsOf(thread *starlark.Thread, chart *chartImpl, k k8s.K8s) k8s.Stream {
+	r.once.Do(func() {
+		r.objects = map[*chartImpl][]*k8s.Object{}
+		s := r.render(thread, r.root, k)
+		if images := r.root.imageConfig(); !images.empty() {
+			s = images.imagePostRenderer(thread, s)
+		}
+		if r.root.postRenderExec != "" {
+			s = executablePostRenderer(r.root.postRenderExec)(thread, s)
+		}
+		r.err = k8s.Decode(s)(func(obj *k8s.Object) error {
+			owner := r.root
+			if i, err := strconv.Atoi(obj.MetaData.Annotations[renderedByAnnotation]); err == nil && i < len(r.charts) {
+				owner = r.charts[i]
+			}
+			delete(obj.MetaData.Annotations, renderedByAnnotation)
+			r.objects[owner] = append(r.objects[owner], obj)
+			return nil
+		})
+	})
+	if r.err != nil {
+		return k8s.ErrorStream(r.err)
+	}
+	return k8s.ObjectStream(func(w k8s.ObjectConsumer) error {
+		for _, obj := range r.objects[chart] {
+			if err := w(obj); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Encode()
+}
+
+// render renders the objects of the subcharts followed by the objects of the chart and passes them to the post_render
+// method of the chart. Objects without annotation are attributed to the chart.
+func (r *treeRender) render(thread *starlark.Thread, chart *chartImpl, k k8s.K8s) k8s.Stream {
+	streams := []k8s.Stream{}
+	err := chart.subChartGraph(false).run(1, func(subChart *chartImpl) error {
+		for _, c := range r.charts {
+			if c == subChart {
+				return nil
+			}
+		}
+		streams = append(streams, r.render(thread, subChart, k))
+		return nil
+	})
+	if err != nil {
+		return k8s.ErrorStream(err)
+	}
+	r.charts = append(r.charts, chart)
+	index := strconv.Itoa(len(r.charts) - 1)
+	annotate := func(obj *k8s.Object) *k8s.Object {
+		if _, ok := obj.MetaData.Annotations[renderedByAnnotation]; !ok {
+			if obj.MetaData.Annotations == nil {
+				obj.MetaData.Annotations = map[string]string{}
+			}
+			obj.MetaData.Annotations[renderedByAnnotation] = index
+		}
+		return obj
+	}
+	s := k8s.YamlConcat(append(streams, k8s.Decode(chart.renderLocal(thread, "", k)).Map(annotate).Encode())...)
+	if _, ok := chart.methods["post_render"]; ok {
+		s = k8s.Decode(chart.starlarkPostRenderer(thread, s)).Map(annotate).Encode()
+	}
+	return s
+}
+
+// starlarkPostRenderer calls post_render(self, objects) of Chart.star with the list of rendered objects
+func (c *chartImpl) starlarkPostRenderer(thread *starlark.Thread, s k8s.Stream) k8s.Stream {
+	return func(w io.Writer) error {
+		objects := []interface{}{}
+		err := k8s.Decode(s)(func(obj *k8s.Object) error {
+			data, err := json.Marshal(obj)
+			if err != nil {
+				return err
+			}
+			var object map[string]interface{}
+			if err := json.Unmarshal(data, &object); err != nil {
+				return err
+			}
+			objects = append(objects, object)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		value, err := starlark.Call(thread, c.methods["post_render"], starlark.Tuple{starutils.ToStarlark(objects)}, nil)
+		if err != nil {
+			return err
+		}
+		if _, ok := value.(*starlark.List); !ok {
+			return fmt.Errorf("post_render of chart %s must return a list of objects, got %s", c.GetName(), value.Type())
+		}
+		for _, object := range starutils.ToGo(value).([]interface{}) {
+			data, err := json.Marshal(object)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "\n---\n%s\n", data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// executablePostRenderer passes the rendered objects as yaml to stdin of an executable and reads the modified objects
+// from its stdout like helm --post-renderer
+func executablePostRenderer(executable string) postRenderer {
+	return func(thread *starlark.Thread, s k8s.Stream) k8s.Stream {
+		return func(w io.Writer) error {
+			input := &bytes.Buffer{}
+			err := k8s.Decode(s)(func(obj *k8s.Object) error {
+				data, err := json.Marshal(obj)
+				if err != nil {
+					return err
+				}
+				if data, err = yaml.JSONToYAML(data); err != nil {
+					return err
+				}
+				_, err = fmt.Fprintf(input, "---\n%s", data)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			output := &bytes.Buffer{}
+			cmd := exec.CommandContext(GetContext(thread), executable)
+			cmd.Stdin = input
+			cmd.Stdout = output
+			cmd.Stderr = os.Stderr
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("error running post renderer %s: %w", executable, err)
+			}
+			_, err = w.Write(output.Bytes())
+			return err
+		}
+	}
+}
+
+// helmPostRenderer replaces the manifest of a helm release with the post rendered objects of the chart
+type helmPostRenderer struct {
+	thread *starlark.Thread
+	chart  *chartImpl
+	k8s    k8s.K8s
+}
+
+// Run -
+func (h *helmPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	result := &bytes.Buffer{}
+	err := h.chart.treeRender.objectsOf(h.thread, h.chart, h.k8s)(result)
+	return result, err
+}
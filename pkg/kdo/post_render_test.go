@@ -0,0 +1,96 @@
+package kdo
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Post render", func() {
+	var dir TestDir
+	var k *k8s.K8sInMemory
+	thread := &starlark.Thread{Name: "main"}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		k = k8s.NewK8sInMemory("test")
+		dir.MkdirAll("templates", 0755)
+		dir.WriteFile("Chart.yaml", []byte("name: app\nversion: 1.0.0\n"), 0644)
+		dir.WriteFile("templates/cm.yaml", []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"), 0644)
+		dir.MkdirAll("sub/templates", 0755)
+		dir.WriteFile("sub/Chart.yaml", []byte("name: sub\nversion: 1.0.0\n"), 0644)
+		dir.WriteFile("sub/templates/cm.yaml", []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: sub\n"), 0644)
+		dir.WriteFile("sub/templates/secret.yaml", []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: sub\n"), 0644)
+		dir.WriteFile("Chart.star", []byte(`
+def init(self):
+	self.sub = chart("sub")
+
+def post_render(self, objects):
+	result = []
+	for obj in objects:
+		if obj["kind"] == "Secret":
+			continue
+		obj["metadata"]["labels"] = {"team": "blue"}
+		result.append(obj)
+	return result
+`), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	get := func(kind string, name string) *k8s.Object {
+		obj, err := k.Get(kind, name, &k8s.Options{Namespace: "test", IgnoreNotFound: true})
+		Expect(err).NotTo(HaveOccurred())
+		return obj
+	}
+
+	It("modifies the objects of the chart and its subcharts", func() {
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), WithNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Apply(thread, k)).To(Succeed())
+		Expect(get("configmap", "app").MetaData.Labels).To(HaveKeyWithValue("team", "blue"))
+		Expect(get("configmap", "sub").MetaData.Labels).To(HaveKeyWithValue("team", "blue"))
+		Expect(get("secret", "sub")).To(BeNil())
+	})
+
+	It("runs a post renderer executable", func() {
+		dir.WriteFile("rename.sh", []byte("#!/bin/sh\nsed 's/name: sub/name: renamed/'\n"), 0755)
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Join("sub"), WithPostRenderer(dir.Join("rename.sh")))
+		Expect(err).NotTo(HaveOccurred())
+		buffer := &bytes.Buffer{}
+		Expect(c.Template(thread, k)(buffer)).To(Succeed())
+		Expect(buffer.String()).To(ContainSubstring(`"name":"renamed"`))
+		Expect(buffer.String()).NotTo(ContainSubstring(`"name":"sub"`))
+	})
+
+	It("runs the post renderer executable once for the chart and its subcharts", func() {
+		dir.WriteFile("count.sh", []byte("#!/bin/sh\necho run >> "+dir.Join("calls")+"\nsed 's/name: sub/name: renamed/'\n"), 0755)
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), WithNamespace("test"), WithPostRenderer(dir.Join("count.sh")))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Template(thread, k)(&bytes.Buffer{})).To(Succeed())
+		Expect(ioutil.ReadFile(dir.Join("calls"))).To(Equal([]byte("run\n")))
+
+		Expect(c.Apply(thread, k)).To(Succeed())
+		Expect(ioutil.ReadFile(dir.Join("calls"))).To(Equal([]byte("run\nrun\n")))
+		Expect(get("configmap", "renamed").MetaData.Labels).To(HaveKeyWithValue("team", "blue"))
+		Expect(get("configmap", "renamed").MetaData.Annotations).NotTo(HaveKey(renderedByAnnotation))
+		Expect(get("configmap", "app")).NotTo(BeNil())
+	})
+
+	It("reports invalid results", func() {
+		dir.WriteFile("Chart.star", []byte("def post_render(self, objects):\n\treturn None\n"), 0644)
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Template(thread, k)(&bytes.Buffer{})).To(MatchError("post_render of chart app must return a list of objects, got NoneType"))
+	})
+})
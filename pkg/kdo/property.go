@@ -3,9 +3,14 @@ package kdo
 import (
 	"errors"
 	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/sap/kubernetes-deployment-orchestrator/pkg/starutils"
+	"gopkg.in/yaml.v2"
 )
 
 // ReadProperty -
@@ -26,30 +31,117 @@ type PropertyValue interface {
 	starlark.HasAttrs
 }
 
+// propertyTypes are the types supported by property(type=...). An empty type accepts any value.
+var propertyTypes = []string{"string", "int", "bool", "float", "list", "dict", "enum", "secret"}
+
 type property struct {
-	typ   string
-	value starlark.Value
-	dflt  starlark.Value
+	typ         string
+	value       starlark.Value
+	dflt        starlark.Value
+	required    bool
+	choices     []starlark.Value
+	pattern     *regexp.Regexp
+	min         starlark.Value
+	max         starlark.Value
+	description string
 }
 
 var _ PropertyValue = (*property)(nil)
 var _ starutils.GoConvertible = (*property)(nil)
 
 func newProperty(dflt starlark.Value) *property {
-	return &property{value: starlark.None, typ: "string", dflt: dflt}
+	return &property{value: starlark.None, dflt: dflt, min: starlark.None, max: starlark.None}
 }
 
 func makeProperty(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (value starlark.Value, e error) {
 	s := newProperty(starlark.None)
-	var err error
-	if err = starlark.UnpackArgs("property", args, kwargs, "type?", &s.typ, "default?", &s.dflt); err != nil {
+	var choices starlark.Iterable
+	var pattern string
+	if err := starlark.UnpackArgs("property", args, kwargs, "type?", &s.typ, "default?", &s.dflt, "required?", &s.required,
+		"choices?", &choices, "pattern?", &pattern, "min?", &s.min, "max?", &s.max, "description?", &s.description); err != nil {
 		return nil, err
 	}
+	if choices != nil {
+		iter := choices.Iterate()
+		defer iter.Done()
+		var choice starlark.Value
+		for iter.Next(&choice) {
+			s.choices = append(s.choices, choice)
+		}
+	}
+	if pattern != "" {
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("property: invalid pattern %s: %s", pattern, err.Error())
+		}
+		s.pattern = r
+	}
+	for _, bound := range []starlark.Value{s.min, s.max} {
+		if _, ok := starlark.AsFloat(bound); bound != starlark.None && !ok {
+			return nil, fmt.Errorf("property: min and max must be numbers not %s", bound.Type())
+		}
+	}
+	if s.typ == "" && len(s.choices) != 0 {
+		s.typ = "enum"
+	}
+	if s.typ != "" && !isPropertyType(s.typ) {
+		return nil, fmt.Errorf("property: unknown type %s, must be one of %s", s.typ, strings.Join(propertyTypes, ", "))
+	}
+	if s.typ == "enum" && len(s.choices) == 0 {
+		return nil, fmt.Errorf("property: type enum requires choices")
+	}
+	dflt, err := s.coerce(s.dflt)
+	if err != nil {
+		return nil, fmt.Errorf("property: invalid default: %s", err.Error())
+	}
+	s.dflt = dflt
 	return s, nil
 }
 
+func isPropertyType(typ string) bool {
+	for _, t := range propertyTypes {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// inferType returns the property type of a value
+func inferType(dflt starlark.Value) string {
+	switch dflt.(type) {
+	case starlark.String:
+		return "string"
+	case starlark.Int:
+		return "int"
+	case starlark.Float:
+		return "float"
+	case starlark.Bool:
+		return "bool"
+	case *starlark.List, starlark.Tuple:
+		return "list"
+	case starlark.IterableMapping:
+		return "dict"
+	}
+	return ""
+}
+
 func (s *property) String() string {
-	return fmt.Sprintf("property(type = %s, value = %v , default = %v)", s.typ, s.value, s.dflt)
+	typ := s.typ
+	if typ == "" {
+		typ = "any"
+	}
+	if s.typ == "secret" {
+		return fmt.Sprintf("property(type = %s, value = %s , default = %s)", typ, mask(s.value), mask(s.dflt))
+	}
+	return fmt.Sprintf("property(type = %s, value = %v , default = %v)", typ, s.value, s.dflt)
+}
+
+func mask(value starlark.Value) string {
+	if value == starlark.None {
+		return value.String()
+	}
+	return "******"
 }
 
 func (s *property) Type() string {
@@ -76,10 +168,181 @@ func (s *property) SetValue(value starlark.Value) error {
 	if ok {
 		value = o.GetValueOrDefault()
 	}
+	value, err := s.coerce(value)
+	if err != nil {
+		return err
+	}
 	s.value = value
 	return nil
 }
 
+// coerce converts value into the type of the property and checks its constraints. Strings, e.g. passed
+// with --set, are parsed if the property has a different type. None is always accepted.
+func (s *property) coerce(value starlark.Value) (starlark.Value, error) {
+	if value == starlark.None {
+		return value, nil
+	}
+	typ := s.typ
+	if _, ok := value.(starlark.String); ok && typ == "" {
+		// untyped properties, e.g. from values.yaml, keep the type of their default if possible
+		if t := inferType(s.dflt); t == "int" || t == "float" || t == "bool" {
+			if v, err := convert(t, value); err == nil {
+				value = v
+			}
+		}
+	}
+	value, err := convert(typ, value)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.choices) != 0 {
+		choice, ok := s.choice(value)
+		if !ok {
+			return nil, fmt.Errorf("%s is not one of %s", s.describe(value), starlark.Tuple(s.choices).String())
+		}
+		value = choice
+	}
+	if str, ok := value.(starlark.String); ok && s.pattern != nil && !s.pattern.MatchString(str.GoString()) {
+		return nil, fmt.Errorf("%s does not match pattern %s", s.describe(value), s.pattern.String())
+	}
+	return value, s.checkBounds(value)
+}
+
+func convert(typ string, value starlark.Value) (starlark.Value, error) {
+	str, isString := value.(starlark.String)
+	switch typ {
+	case "string", "secret":
+		if isString {
+			return value, nil
+		}
+	case "int":
+		switch v := value.(type) {
+		case starlark.Int:
+			return v, nil
+		case starlark.Float:
+			if float64(v) == math.Trunc(float64(v)) {
+				return starlark.MakeInt64(int64(v)), nil
+			}
+		case starlark.String:
+			if i, err := strconv.ParseInt(strings.TrimSpace(v.GoString()), 0, 64); err == nil {
+				return starlark.MakeInt64(i), nil
+			}
+		}
+	case "float":
+		switch v := value.(type) {
+		case starlark.Float:
+			return v, nil
+		case starlark.Int:
+			return v.Float(), nil
+		case starlark.String:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v.GoString()), 64); err == nil {
+				return starlark.Float(f), nil
+			}
+		}
+	case "bool":
+		switch v := value.(type) {
+		case starlark.Bool:
+			return v, nil
+		case starlark.String:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v.GoString())); err == nil {
+				return starlark.Bool(b), nil
+			}
+		}
+	case "list":
+		if isString {
+			value = parseYamlValue(str.GoString())
+		}
+		switch v := value.(type) {
+		case *starlark.List:
+			return v, nil
+		case starlark.Tuple:
+			return starlark.NewList(append([]starlark.Value{}, v...)), nil
+		}
+	case "dict":
+		if isString {
+			value = parseYamlValue(str.GoString())
+		}
+		if _, ok := value.(starlark.IterableMapping); ok {
+			return value, nil
+		}
+	default:
+		return value, nil
+	}
+	if typ == "secret" {
+		return nil, fmt.Errorf("expected %s, got %s %s", typ, value.Type(), mask(value))
+	}
+	return nil, fmt.Errorf("expected %s, got %s %s", typ, value.Type(), value.String())
+}
+
+// parseYamlValue parses a list or dict given as string, e.g. --set ports=[80,443]. It returns the string itself if it isn't valid yaml.
+func parseYamlValue(str string) starlark.Value {
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(str), &v); err != nil {
+		return starlark.String(str)
+	}
+	return starutils.ToStarlark(v)
+}
+
+func (s *property) choice(value starlark.Value) (starlark.Value, bool) {
+	for _, choice := range s.choices {
+		if equal, err := starlark.Equal(choice, value); err == nil && equal {
+			return choice, true
+		}
+	}
+	if str, ok := value.(starlark.String); ok {
+		for _, choice := range s.choices {
+			if c, ok := choice.(starlark.String); ok && c == str || !ok && choice.String() == str.GoString() {
+				return choice, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// checkBounds checks min and max. They limit numbers or the length of strings, lists and dicts.
+func (s *property) checkBounds(value starlark.Value) error {
+	if s.min == starlark.None && s.max == starlark.None {
+		return nil
+	}
+	subject := s.describe(value)
+	n, ok := starlark.AsFloat(value)
+	if !ok {
+		sequence, ok := value.(starlark.Sequence)
+		if ok {
+			n = float64(sequence.Len())
+		} else if str, ok := value.(starlark.String); ok {
+			n = float64(str.Len())
+		} else if mapping, ok := value.(starlark.IterableMapping); ok {
+			n = float64(len(mapping.Items()))
+		} else {
+			return nil
+		}
+		subject = "length of " + subject
+	}
+	if min, ok := starlark.AsFloat(s.min); ok && n < min {
+		return fmt.Errorf("%s is less than %s", subject, s.min.String())
+	}
+	if max, ok := starlark.AsFloat(s.max); ok && n > max {
+		return fmt.Errorf("%s is greater than %s", subject, s.max.String())
+	}
+	return nil
+}
+
+func (s *property) describe(value starlark.Value) string {
+	if s.typ == "secret" {
+		return fmt.Sprintf("%s %s", value.Type(), mask(value))
+	}
+	return fmt.Sprintf("%s %s", value.Type(), value.String())
+}
+
+// validate reports required properties without value
+func (s *property) validate() error {
+	if s.required && s.GetValueOrDefault() == starlark.None {
+		return errors.New("required property is not set")
+	}
+	return nil
+}
+
 func (s *property) GetValue() starlark.Value {
 	return s.value
 }
@@ -1,7 +1,12 @@
 package kdo
 
 import (
+	"bytes"
+	"errors"
+
 	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -14,7 +19,7 @@ var _ = Describe("Property", func() {
 		d, err := makeProperty(thread, nil, nil, kwargs)
 		Expect(err).NotTo(HaveOccurred())
 		s := d.(*property)
-		Expect(s.String()).To(ContainSubstring("type = string"))
+		Expect(s.String()).To(ContainSubstring("type = any"))
 		Expect(s.Truth()).To(BeEquivalentTo(false))
 		Expect(s.Type()).To(Equal("property"))
 		_, err = s.Hash()
@@ -35,4 +40,159 @@ var _ = Describe("Property", func() {
 
 	})
 
+	newProperty := func(kwargs ...starlark.Tuple) (*property, error) {
+		p, err := makeProperty(&starlark.Thread{Name: "main"}, nil, nil, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return p.(*property), nil
+	}
+	kwarg := func(name string, value starlark.Value) starlark.Tuple {
+		return starlark.Tuple{starlark.String(name), value}
+	}
+
+	It("checks the type only if it's given", func() {
+		p, err := newProperty(kwarg("default", starlark.MakeInt(0)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.typ).To(Equal(""))
+		Expect(p.SetValue(starlark.Float(1.5))).To(Succeed())
+		Expect(p.SetValue(starlark.String("3"))).To(Succeed())
+		Expect(p.GetValue()).To(Equal(starlark.MakeInt(3)))
+		p, _ = newProperty(kwarg("default", starlark.String("8080")))
+		Expect(p.SetValue(starlark.MakeInt(8080))).To(Succeed())
+		Expect(p.GetValue()).To(Equal(starlark.MakeInt(8080)))
+		p, err = newProperty()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.String()).To(ContainSubstring("type = any"))
+		Expect(p.SetValue(starlark.MakeInt(1))).To(Succeed())
+		p, err = newProperty(kwarg("choices", starlark.NewList([]starlark.Value{starlark.String("a")})))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.typ).To(Equal("enum"))
+	})
+
+	It("coerces strings", func() {
+		p, err := newProperty(kwarg("type", starlark.String("int")))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.SetValue(starlark.String("3"))).To(Succeed())
+		Expect(p.GetValue()).To(Equal(starlark.MakeInt(3)))
+		Expect(p.SetValue(starlark.String("three"))).To(MatchError(`expected int, got string "three"`))
+
+		p, _ = newProperty(kwarg("type", starlark.String("bool")))
+		Expect(p.SetValue(starlark.String("true"))).To(Succeed())
+		Expect(p.GetValue()).To(Equal(starlark.True))
+
+		p, _ = newProperty(kwarg("type", starlark.String("float")))
+		Expect(p.SetValue(starlark.MakeInt(2))).To(Succeed())
+		Expect(p.GetValue()).To(Equal(starlark.Float(2)))
+
+		p, _ = newProperty(kwarg("type", starlark.String("list")))
+		Expect(p.SetValue(starlark.String("[80, 443]"))).To(Succeed())
+		Expect(p.GetValue().String()).To(Equal("[80, 443]"))
+
+		p, _ = newProperty(kwarg("type", starlark.String("dict")))
+		Expect(p.SetValue(starlark.String("{a: b}"))).To(Succeed())
+		Expect(p.SetValue(starlark.String("a"))).To(HaveOccurred())
+
+		p, _ = newProperty(kwarg("type", starlark.String("string")))
+		Expect(p.SetValue(starlark.MakeInt(1))).To(HaveOccurred())
+		Expect(p.SetValue(starlark.None)).To(Succeed())
+	})
+
+	It("checks constraints", func() {
+		p, err := newProperty(kwarg("type", starlark.String("enum")), kwarg("choices", starlark.Tuple{starlark.MakeInt(1), starlark.MakeInt(2)}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.SetValue(starlark.String("2"))).To(Succeed())
+		Expect(p.GetValue()).To(Equal(starlark.MakeInt(2)))
+		Expect(p.SetValue(starlark.MakeInt(3))).To(MatchError("int 3 is not one of (1, 2)"))
+
+		p, _ = newProperty(kwarg("pattern", starlark.String("^[a-z]+$")))
+		Expect(p.SetValue(starlark.String("abc"))).To(Succeed())
+		Expect(p.SetValue(starlark.String("ABC"))).To(MatchError(`string "ABC" does not match pattern ^[a-z]+$`))
+
+		p, _ = newProperty(kwarg("type", starlark.String("int")), kwarg("min", starlark.MakeInt(1)), kwarg("max", starlark.MakeInt(5)))
+		Expect(p.SetValue(starlark.String("5"))).To(Succeed())
+		Expect(p.SetValue(starlark.MakeInt(0))).To(MatchError("int 0 is less than 1"))
+		Expect(p.SetValue(starlark.MakeInt(6))).To(MatchError("int 6 is greater than 5"))
+
+		p, _ = newProperty(kwarg("type", starlark.String("string")), kwarg("max", starlark.MakeInt(2)))
+		Expect(p.SetValue(starlark.String("abc"))).To(MatchError(`length of string "abc" is greater than 2`))
+
+		_, err = newProperty(kwarg("type", starlark.String("integer")))
+		Expect(err).To(HaveOccurred())
+		_, err = newProperty(kwarg("type", starlark.String("enum")))
+		Expect(err).To(HaveOccurred())
+		_, err = newProperty(kwarg("type", starlark.String("int")), kwarg("default", starlark.String("x")))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("masks secrets", func() {
+		p, err := newProperty(kwarg("type", starlark.String("secret")), kwarg("min", starlark.MakeInt(8)))
+		Expect(err).NotTo(HaveOccurred())
+		err = p.SetValue(starlark.String("geheim"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).NotTo(ContainSubstring("geheim"))
+		Expect(p.SetValue(starlark.String("geheimnis"))).To(Succeed())
+		Expect(p.String()).NotTo(ContainSubstring("geheimnis"))
+		Expect(p.ToGo()).To(Equal("geheimnis"))
+	})
+
+	Context("chart", func() {
+		var dir TestDir
+		thread := &starlark.Thread{Name: "main"}
+
+		BeforeEach(func() {
+			dir = NewTestDir()
+			dir.WriteFile("Chart.star", []byte(`
+def init(self):
+	self.replicas = property(type = "int", default = 1, min = 1)
+	self.mode = property(choices = ["fast", "safe"], default = "safe")
+	self.password = property(type = "secret", required = True)
+	self.db = struct_property(port = property(type = "int"))
+def template(self):
+	return "{}"
+`), 0644)
+		})
+		AfterEach(func() {
+			dir.Remove()
+		})
+
+		It("coerces values", func() {
+			repo, _ := NewRepo()
+			c, err := newChart(thread, repo, dir.Root(), WithValues(map[string]interface{}{"replicas": "3", "db": map[string]interface{}{"port": "5432"}}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.values["replicas"].(*property).GetValue()).To(Equal(starlark.MakeInt(3)))
+			Expect(c.validate()).To(MatchError("password: required property is not set"))
+			err = c.Template(thread, k8s.NewK8sInMemory("test"))(&bytes.Buffer{})
+			Expect(err).To(MatchError("invalid values for chart " + c.GetName() + ": password: required property is not set"))
+			Expect(c.SetField("password", starlark.String("secret"))).To(Succeed())
+			Expect(c.Template(thread, k8s.NewK8sInMemory("test"))(&bytes.Buffer{})).To(Succeed())
+		})
+
+		It("aggregates errors", func() {
+			repo, _ := NewRepo()
+			_, err := newChart(thread, repo, dir.Root(), WithValues(map[string]interface{}{"replicas": "0", "mode": "slow", "db": map[string]interface{}{"port": "x"}}))
+			Expect(err).To(HaveOccurred())
+			validationError := &ValidationError{}
+			Expect(errors.As(err, &validationError)).To(BeTrue())
+			Expect(validationError.Issues).To(ConsistOf(
+				ValidationIssue{Path: "replicas", Message: "int 0 is less than 1"},
+				ValidationIssue{Path: "mode", Message: `string "slow" is not one of ("fast", "safe")`},
+				ValidationIssue{Path: "db.port", Message: `expected int, got string "x"`},
+			))
+		})
+
+		It("passes only valid values to init", func() {
+			dir.WriteFile("Chart.star", []byte(`
+def init(self):
+	self.replicas = property(type = "int", default = 1, min = 1)
+	self.pods = self.replicas + 1
+`), 0644)
+			repo, _ := NewRepo()
+			c, err := newChart(thread, repo, dir.Root(), WithValues(map[string]interface{}{"replicas": "3"}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.values["pods"]).To(Equal(starlark.MakeInt(4)))
+			_, err = newChart(thread, repo, dir.Root(), WithValues(map[string]interface{}{"replicas": "0"}))
+			Expect(err).To(MatchError("invalid values for chart " + c.GetName() + ": replicas: int 0 is less than 1"))
+		})
+	})
 })
@@ -0,0 +1,108 @@
+package kdo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// RekeyOptions -
+type RekeyOptions struct {
+	RepoListOptions
+	oldEncryptionKey string
+	rotate           bool
+}
+
+// keyRotator is implemented by key providers which can create a new current key
+type keyRotator interface {
+	Rotate() error
+}
+
+// Rekey re-encrypts the values of all installed charts selected by options with the encryption key
+func Rekey(k k8s.K8s, options *RekeyOptions, writer io.Writer) error {
+	if options.encryptionKey == "" {
+		return fmt.Errorf("new encryption key is required (use --encryption-key)")
+	}
+	// rotating creates a missing key ring with its first key
+	provider, err := newKeyProvider(options.encryptionKey, options.rotate)
+	if err != nil {
+		return err
+	}
+	oldProvider := provider
+	if options.oldEncryptionKey != "" && options.oldEncryptionKey != options.encryptionKey {
+		if oldProvider, err = NewKeyProvider(options.oldEncryptionKey); err != nil {
+			return err
+		}
+	}
+	if options.rotate {
+		rotator, ok := provider.(keyRotator)
+		if !ok {
+			return fmt.Errorf("%s provider doesn't support key rotation", provider.Name())
+		}
+		// the key ring keeps the old keys, so the provider can still decrypt existing values
+		if err := rotator.Rotate(); err != nil {
+			return err
+		}
+	}
+	secrets, err := chartSecrets(k, &options.RepoListOptions)
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets {
+		reencrypt := func(obj *k8s.Object) error {
+			values, err := secretValues(obj, oldProvider)
+			if err != nil {
+				return fmt.Errorf("error decrypting values of secret %s in namespace %s: %w", obj.MetaData.Name, secret.MetaData.Namespace, err)
+			}
+			return setSecretValues(obj, values, provider)
+		}
+		if _, err := k.CreateOrUpdate(&secret, reencrypt, &k8s.Options{Namespace: secret.MetaData.Namespace, Quiet: true}); err != nil {
+			return err
+		}
+		fmt.Fprintf(writer, "Re-encrypted values of chart %s in namespace %s\n", secret.MetaData.Labels["kdo.sap.github.com/genus"], secret.MetaData.Namespace)
+	}
+	return nil
+}
+
+// chartSecrets returns the secrets of all installed charts selected by options
+func chartSecrets(k k8s.K8s, options *RepoListOptions) ([]k8s.Object, error) {
+	selector := labels.NewSelector()
+	requirement, err := labels.NewRequirement("kdo.sap.github.com/chart", selection.Equals, []string{"true"})
+	if err != nil {
+		return nil, err
+	}
+	selector = selector.Add(*requirement)
+	if len(options.genus) != 0 {
+		requirement, err := labels.NewRequirement("kdo.sap.github.com/genus", selection.Equals, []string{options.genus})
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*requirement)
+	}
+	if selector, err = options.selectSuffix(selector); err != nil {
+		return nil, err
+	}
+	k8sOptions := &k8s.Options{Quiet: true, Namespace: options.namespace, ClusterScoped: options.allNamespaces}
+	obj, err := k.List("secrets", k8sOptions, &k8s.ListOptions{LabelSelector: selector, AllNamespaces: options.allNamespaces})
+	if err != nil {
+		return nil, err
+	}
+	var items []k8s.Object
+	if err := json.Unmarshal(obj.Additional["items"], &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// AddFlags -
+func (s *RekeyOptions) AddFlags(flagsSet *pflag.FlagSet) {
+	s.RepoListOptions.AddFlags(flagsSet)
+	flagsSet.Lookup("encryption-key").Usage = "Encrypt the values stored in the cluster with this key"
+	flagsSet.StringVar(&s.oldEncryptionKey, "old-encryption-key", "", "Decrypt the values stored in the cluster with this key, defaults to --encryption-key")
+	flagsSet.BoolVar(&s.rotate, "rotate", false, "Create a new key in the key ring, or the key ring itself, before re-encrypting (kms provider only)")
+}
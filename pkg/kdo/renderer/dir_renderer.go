@@ -13,6 +13,9 @@ type DirSpec struct {
 	FileRenderer func(filename string) func(writer io.Writer) error
 }
 
+// NotesFile is rendered after installation instead of being part of the manifest
+const NotesFile = "NOTES.txt"
+
 // DirRender -
 func DirRender(glob string, specs ...DirSpec) func(io.Writer) error {
 	if glob == "" {
@@ -26,7 +29,7 @@ func DirRender(glob string, specs ...DirSpec) func(io.Writer) error {
 				if err != nil {
 					return err
 				}
-				if !info.IsDir() {
+				if !info.IsDir() && info.Name() != NotesFile {
 					match, err := filepath.Match(glob, path.Base(file))
 					if err != nil {
 						return err
@@ -1,9 +1,14 @@
 package renderer
 
 import (
+	"encoding/base64"
 	"io/ioutil"
+	"os"
 	"path"
 	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/glob"
 )
 
 // Files -
@@ -11,32 +16,110 @@ type Files struct {
 	Dir string
 }
 
-// Glob -
-func (f Files) Glob(pattern string) map[string][]byte {
-	result := make(map[string][]byte)
-	matches, err := filepath.Glob(path.Join(f.Dir, pattern))
+// FileContents are the contents of files by their path relative to the chart
+type FileContents map[string][]byte
+
+// Glob returns the files matching the pattern. Like helm, ** matches across directories.
+func (f Files) Glob(pattern string) FileContents {
+	result := make(FileContents)
+	g, err := glob.Compile(pattern, '/')
 	if err != nil {
 		return result
 	}
-	for _, match := range matches {
-		data, err := ioutil.ReadFile(match)
-		if err == nil {
-			p, err := filepath.Rel(f.Dir, match)
-			if err == nil {
-				result[p] = data
-			} else {
-			}
-		} else {
+	for name, data := range f.all() {
+		if g.Match(name) {
+			result[name] = data
 		}
 	}
 	return result
 }
 
-// Get -
+// Get returns the content of a file or an empty string, if the file can't be read
 func (f Files) Get(name string) string {
+	return string(f.GetBytes(name))
+}
+
+// GetBytes returns the content of a file or nil, if the file can't be read
+func (f Files) GetBytes(name string) []byte {
 	data, err := ioutil.ReadFile(path.Join(f.Dir, name))
 	if err != nil {
-		return err.Error()
+		return nil
+	}
+	return data
+}
+
+// Lines returns the lines of a file
+func (f Files) Lines(name string) []string {
+	s := strings.TrimSuffix(f.Get(name), "\n")
+	if s == "" {
+		return []string{}
 	}
-	return string(data)
+	return strings.Split(s, "\n")
+}
+
+// AsConfig returns all files of the chart as data of a config map
+func (f Files) AsConfig() string {
+	return f.all().AsConfig()
+}
+
+// AsSecrets returns all files of the chart base64 encoded as data of a secret
+func (f Files) AsSecrets() string {
+	return f.all().AsSecrets()
+}
+
+// all returns the files of the chart, which aren't templates, subcharts or chart metadata
+func (f Files) all() FileContents {
+	result := make(FileContents)
+	filepath.Walk(f.Dir, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(f.Dir, name)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			switch rel {
+			case "templates", "charts", "ytt-templates", "kustomize":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch rel {
+		case "Chart.yaml", "Chart.star", "values.yaml", "requirements.yaml", "requirements.lock", "Chart.lock":
+			return nil
+		}
+		if data, err := ioutil.ReadFile(name); err == nil {
+			result[rel] = data
+		}
+		return nil
+	})
+	return result
+}
+
+// AsConfig returns the files as data of a config map
+func (f FileContents) AsConfig() string {
+	if len(f) == 0 {
+		return ""
+	}
+	data := make(map[string]string)
+	for name, content := range f {
+		data[path.Base(name)] = string(content)
+	}
+	result, _ := toYAML(data)
+	return result
+}
+
+// AsSecrets returns the files base64 encoded as data of a secret
+func (f FileContents) AsSecrets() string {
+	if len(f) == 0 {
+		return ""
+	}
+	data := make(map[string]string)
+	for name, content := range f {
+		data[path.Base(name)] = base64.StdEncoding.EncodeToString(content)
+	}
+	result, _ := toYAML(data)
+	return result
 }
@@ -19,6 +19,28 @@ var _ = Describe("files", func() {
 		Expect(content).To(HaveKeyWithValue("file2.yaml", []byte("aaaa")))
 
 		Expect(f.Get("file2.yaml")).To(Equal("aaaa"))
-		Expect(f.Get("file3.yaml")).To(ContainSubstring("no such file"))
+		Expect(f.Get("file3.yaml")).To(BeEmpty())
+		Expect(f.GetBytes("file1.yaml")).To(Equal([]byte("1234")))
+	})
+
+	It("provides helm helpers", func() {
+		dir := NewTestDir()
+		defer dir.Remove()
+		dir.MkdirAll("conf/nested", 0755)
+		dir.MkdirAll("templates", 0755)
+		dir.WriteFile("conf/a.conf", []byte("a=1\nb=2\n"), 0644)
+		dir.WriteFile("conf/nested/c.conf", []byte("c=3"), 0644)
+		dir.WriteFile("templates/cm.yaml", []byte("kind: ConfigMap"), 0644)
+		dir.WriteFile("Chart.yaml", []byte("name: test"), 0644)
+		f := Files{Dir: dir.Root()}
+
+		Expect(f.Lines("conf/a.conf")).To(Equal([]string{"a=1", "b=2"}))
+		Expect(f.Lines("missing")).To(BeEmpty())
+		Expect(f.Glob("conf/*")).To(HaveLen(1))
+		Expect(f.Glob("conf/**")).To(HaveLen(2))
+		Expect(f.Glob("conf/*").AsConfig()).To(Equal("a.conf: |\n  a=1\n  b=2"))
+		Expect(f.Glob("conf/nested/*").AsSecrets()).To(Equal("c.conf: Yz0z"))
+		Expect(f.Glob("missing/*").AsConfig()).To(BeEmpty())
+		Expect(f.AsConfig()).To(Equal("a.conf: |\n  a=1\n  b=2\nc.conf: c=3"))
 	})
 })
@@ -14,18 +14,24 @@ import (
 	"text/template"
 
 	yaml "gopkg.in/yaml.v2"
+	k8syaml "sigs.k8s.io/yaml"
 
+	"github.com/BurntSushi/toml"
 	"github.com/Masterminds/sprig/v3"
 )
 
+// LookupFunction returns an object of the cluster like the helm function lookup. If name is empty, all objects of
+// the kind are returned as list.
+type LookupFunction func(apiVersion string, kind string, namespace string, name string) (map[string]interface{}, error)
+
 type helmRenderer struct {
 	helpers string
 	root    *template.Template
 }
 
 // HelmFileRenderer -
-func HelmFileRenderer(dir string, value interface{}) func(filename string) func(writer io.Writer) error {
-	h, err := newHelmRenderer(dir)
+func HelmFileRenderer(dir string, value interface{}, lookup LookupFunction) func(filename string) func(writer io.Writer) error {
+	h, err := newHelmRenderer(dir, lookup)
 	if err != nil {
 		return errorFileRenderer(err)
 	}
@@ -38,7 +44,7 @@ func HelmFileRenderer(dir string, value interface{}) func(filename string) func(
 	return h.fileTemplater(value)
 }
 
-func newHelmRenderer(dir string) (*helmRenderer, error) {
+func newHelmRenderer(dir string, lookup LookupFunction) (*helmRenderer, error) {
 	h := &helmRenderer{root: template.New("root")}
 	content, err := ioutil.ReadFile(path.Join(dir, "templates", "_helpers.tpl"))
 	if err != nil {
@@ -51,13 +57,17 @@ func newHelmRenderer(dir string) (*helmRenderer, error) {
 	}
 	h.root.Funcs(sprig.TxtFuncMap())
 	h.root.Funcs(map[string]interface{}{
-		"toToml":   notImplemented("toToml"),
-		"toYaml":   toYAML,
-		"fromYaml": notImplemented("fromYaml"),
-		"toJson":   toJSON,
-		"fromJson": notImplemented("fromJson"),
-		"tpl":      h.tpl(),
-		"required": required,
+		"toToml":        toTOML,
+		"toYaml":        toYAML,
+		"fromYaml":      fromYAML,
+		"fromYamlArray": fromYAMLArray,
+		"toJson":        toJSON,
+		"mustToJson":    mustToJSON,
+		"fromJson":      fromJSON,
+		"fromJsonArray": fromJSONArray,
+		"tpl":           h.tpl(),
+		"required":      required,
+		"lookup":        lookupOrEmpty(lookup),
 		"include": func(name string, data interface{}) (string, error) {
 			var buf strings.Builder
 			err := h.root.ExecuteTemplate(&buf, name, data)
@@ -99,12 +109,6 @@ func (h *helmRenderer) fileTemplater(value interface{}) func(filename string) fu
 
 }
 
-func notImplemented(name string) func(_ interface{}) (string, error) {
-	return func(_ interface{}) (string, error) {
-		return "", fmt.Errorf("Function %s is not implemented in kdo", name)
-	}
-}
-
 func (h *helmRenderer) loadTemplate(name string) (result *template.Template, err error) {
 	return h.root.New(name), nil
 }
@@ -136,7 +140,42 @@ func toYAML(v interface{}) (string, error) {
 	return strings.TrimSuffix(string(data), "\n"), nil
 }
 
-func toJSON(v interface{}) (string, error) {
+// fromYAML converts a yaml document into a map. Like helm, errors are returned in the key Error.
+func fromYAML(str string) map[string]interface{} {
+	m := map[string]interface{}{}
+	if err := k8syaml.Unmarshal([]byte(str), &m); err != nil {
+		m["Error"] = err.Error()
+	}
+	return m
+}
+
+// fromYAMLArray converts a yaml array into a list. Like helm, errors are returned as only element.
+func fromYAMLArray(str string) []interface{} {
+	a := []interface{}{}
+	if err := k8syaml.Unmarshal([]byte(str), &a); err != nil {
+		a = []interface{}{err.Error()}
+	}
+	return a
+}
+
+func toTOML(v interface{}) string {
+	b := bytes.NewBuffer(nil)
+	if err := toml.NewEncoder(b).Encode(v); err != nil {
+		return err.Error()
+	}
+	return b.String()
+}
+
+// toJSON returns an empty string on errors like helm
+func toJSON(v interface{}) string {
+	result, err := mustToJSON(v)
+	if err != nil {
+		return ""
+	}
+	return result
+}
+
+func mustToJSON(v interface{}) (string, error) {
 	data, err := json.Marshal(v)
 	if err != nil {
 		return "", err
@@ -144,6 +183,34 @@ func toJSON(v interface{}) (string, error) {
 	return strings.TrimSuffix(string(data), "\n"), nil
 }
 
+// fromJSON converts a json document into a map. Like helm, errors are returned in the key Error.
+func fromJSON(str string) map[string]interface{} {
+	m := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(str), &m); err != nil {
+		m["Error"] = err.Error()
+	}
+	return m
+}
+
+// fromJSONArray converts a json array into a list. Like helm, errors are returned as only element.
+func fromJSONArray(str string) []interface{} {
+	a := []interface{}{}
+	if err := json.Unmarshal([]byte(str), &a); err != nil {
+		a = []interface{}{err.Error()}
+	}
+	return a
+}
+
+// lookupOrEmpty returns an empty map, if there is no cluster to look up objects (e.g. for kdo template)
+func lookupOrEmpty(lookup LookupFunction) func(apiVersion string, kind string, namespace string, name string) (map[string]interface{}, error) {
+	return func(apiVersion string, kind string, namespace string, name string) (map[string]interface{}, error) {
+		if lookup == nil {
+			return map[string]interface{}{}, nil
+		}
+		return lookup(apiVersion, kind, namespace, name)
+	}
+}
+
 func required(msg string, c interface{}) (interface{}, error) {
 	if c == nil || (reflect.ValueOf(c).Kind() == reflect.Ptr && reflect.ValueOf(c).IsNil()) {
 		return nil, fmt.Errorf("%s", msg)
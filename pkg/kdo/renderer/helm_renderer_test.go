@@ -22,7 +22,7 @@ var _ = Describe("helmRenderer", func() {
 				Value string
 			}{
 				Value: "test",
-			})
+			}, nil)
 			writer := &bytes.Buffer{}
 			err = helmFileRenderer(dir.Join("test.yaml"))(writer)
 			Expect(err).ToNot(HaveOccurred())
@@ -38,7 +38,7 @@ var _ = Describe("helmRenderer", func() {
 				Value map[string]string
 			}{
 				Value: map[string]string{"key": "value"},
-			})
+			}, nil)
 			writer := &bytes.Buffer{}
 			err = helmFileRenderer(dir.Join("test.yaml"))(writer)
 			Expect(err).ToNot(HaveOccurred())
@@ -54,7 +54,7 @@ var _ = Describe("helmRenderer", func() {
 				Value map[string]string
 			}{
 				Value: map[string]string{"key": "value"},
-			})
+			}, nil)
 			writer := &bytes.Buffer{}
 			err = helmFileRenderer(dir.Join("test.yaml"))(writer)
 			Expect(err).ToNot(HaveOccurred())
@@ -70,7 +70,7 @@ var _ = Describe("helmRenderer", func() {
 					Value string
 				}{
 					Value: "xxx",
-				})
+				}, nil)
 				writer := &bytes.Buffer{}
 				err = helmFileRenderer(dir.Join("test.yaml"))(writer)
 				Expect(err).ToNot(HaveOccurred())
@@ -85,7 +85,7 @@ var _ = Describe("helmRenderer", func() {
 					Value *string
 				}{
 					Value: nil,
-				})
+				}, nil)
 				writer := &bytes.Buffer{}
 				err = helmFileRenderer(dir.Join("test.yaml"))(writer)
 				Expect(err).To(HaveOccurred())
@@ -104,7 +104,7 @@ var _ = Describe("helmRenderer", func() {
 			}{
 				Value:    "value",
 				Template: "{{ .Value }}",
-			})
+			}, nil)
 			writer := &bytes.Buffer{}
 			err = helmFileRenderer(dir.Join("test.yaml"))(writer)
 			Expect(err).ToNot(HaveOccurred())
@@ -123,13 +123,53 @@ var _ = Describe("helmRenderer", func() {
 				Value string
 			}{
 				Value: "value",
-			})
+			}, nil)
 			writer := &bytes.Buffer{}
 			err = helmFileRenderer(dir.Join("test.yaml"))(writer)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(writer.String()).To(Equal("test: value"))
 		})
 
+		It("converts from and to yaml, json and toml", func() {
+			dir := NewTestDir()
+			defer dir.Remove()
+			dir.WriteFile("test.yaml", []byte(`{{ $v := fromYaml .Value }}a: {{ $v.a.b }}
+e: {{ (fromYaml "- x").Error | empty | not }}
+l: {{ index (fromYamlArray "[1, 2]") 1 }}
+j: {{ (fromJson .JSON).x }}
+m: {{ mustToJson $v }}
+t: {{ toToml $v | trim }}`), 0644)
+			helmFileRenderer := HelmFileRenderer(dir.Root(), struct {
+				Value string
+				JSON  string
+			}{
+				Value: "a:\n  b: c\n",
+				JSON:  `{"x": "y"}`,
+			}, nil)
+			writer := &bytes.Buffer{}
+			Expect(helmFileRenderer(dir.Join("test.yaml"))(writer)).To(Succeed())
+			Expect(writer.String()).To(Equal("a: c\ne: true\nl: 2\nj: y\nm: {\"a\":{\"b\":\"c\"}}\nt: [a]\n  b = \"c\""))
+		})
+
+		It("looks up objects", func() {
+			dir := NewTestDir()
+			defer dir.Remove()
+			dir.WriteFile("test.yaml", []byte(`{{ (lookup "v1" "Secret" "ns" "s").kind | default "none" }}-{{ len (lookup "v1" "Secret" "ns" "missing") }}`), 0644)
+			lookup := func(apiVersion string, kind string, namespace string, name string) (map[string]interface{}, error) {
+				if name != "s" {
+					return map[string]interface{}{}, nil
+				}
+				return map[string]interface{}{"kind": kind + "/" + apiVersion + "/" + namespace}, nil
+			}
+			writer := &bytes.Buffer{}
+			Expect(HelmFileRenderer(dir.Root(), nil, lookup)(dir.Join("test.yaml"))(writer)).To(Succeed())
+			Expect(writer.String()).To(Equal("Secret/v1/ns-0"))
+
+			writer.Reset()
+			Expect(HelmFileRenderer(dir.Root(), nil, nil)(dir.Join("test.yaml"))(writer)).To(Succeed())
+			Expect(writer.String()).To(Equal("none-0"))
+		})
+
 		It("it loads helpers", func() {
 			var err error
 			dir := NewTestDir()
@@ -145,7 +185,7 @@ var _ = Describe("helmRenderer", func() {
 				Value string
 			}{
 				Value: "test",
-			})
+			}, nil)
 			writer := &bytes.Buffer{}
 			err = helmFileRenderer(dir.Join("templates/test.yaml"))(writer)
 			Expect(err).ToNot(HaveOccurred())
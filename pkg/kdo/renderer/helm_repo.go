@@ -1,6 +1,7 @@
 package renderer
 
 type Entry struct {
+	Version    string   `yaml:"version,omitempty"`
 	Deprecated bool     `yaml:"deprecated,omitempty"`
 	URLs       []string `yaml:"urls,omitempty"`
 }
@@ -0,0 +1,98 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/yaml"
+)
+
+// KustomizeResourcesFile is the name of the file, which holds the resources passed to KustomizeRender
+const KustomizeResourcesFile = "kdo-resources.yaml"
+
+// kustomizeFileSystem serves additional files, which don't exist on disk
+type kustomizeFileSystem struct {
+	filesys.FileSystem
+	files map[string][]byte
+}
+
+func (f *kustomizeFileSystem) ReadFile(path string) ([]byte, error) {
+	if data, ok := f.files[path]; ok {
+		return data, nil
+	}
+	return f.FileSystem.ReadFile(path)
+}
+
+func (f *kustomizeFileSystem) Exists(path string) bool {
+	_, ok := f.files[path]
+	return ok || f.FileSystem.Exists(path)
+}
+
+func (f *kustomizeFileSystem) CleanedAbs(path string) (filesys.ConfirmedDir, string, error) {
+	if _, ok := f.files[path]; ok {
+		return filesys.ConfirmedDir(filepath.Dir(path)), filepath.Base(path), nil
+	}
+	return f.FileSystem.CleanedAbs(path)
+}
+
+// KustomizeRender runs the kustomization in dir. If resources isn't empty, it's added to the resources of the
+// kustomization as KustomizeResourcesFile.
+func KustomizeRender(dir string, resources []byte) func(writer io.Writer) error {
+	return func(writer io.Writer) error {
+		fs := filesys.MakeFsOnDisk()
+		root, _, err := fs.CleanedAbs(dir)
+		if err != nil {
+			return fmt.Errorf("error running kustomize in %s: %w", dir, err)
+		}
+		fsWithResources := &kustomizeFileSystem{FileSystem: fs, files: map[string][]byte{}}
+		if len(resources) != 0 {
+			if err := fsWithResources.addResources(root.String(), resources); err != nil {
+				return err
+			}
+		}
+		m, err := krusty.MakeKustomizer(fsWithResources, krusty.MakeDefaultOptions()).Run(root.String())
+		if err != nil {
+			return fmt.Errorf("error running kustomize in %s: %w", dir, err)
+		}
+		data, err := m.AsYaml()
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(data)
+		return err
+	}
+}
+
+func (f *kustomizeFileSystem) addResources(root string, resources []byte) error {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		filename := filepath.Join(root, name)
+		data, err := f.ReadFile(filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		var kustomization map[string]interface{}
+		if err := yaml.Unmarshal(data, &kustomization); err != nil {
+			return fmt.Errorf("error parsing %s: %w", filename, err)
+		}
+		if kustomization == nil {
+			kustomization = map[string]interface{}{}
+		}
+		list, _ := kustomization["resources"].([]interface{})
+		kustomization["resources"] = append(list, KustomizeResourcesFile)
+		if data, err = yaml.Marshal(kustomization); err != nil {
+			return err
+		}
+		f.files[filename] = data
+		f.files[filepath.Join(root, KustomizeResourcesFile)] = resources
+		return nil
+	}
+	return fmt.Errorf("no kustomization file found in %s", root)
+}
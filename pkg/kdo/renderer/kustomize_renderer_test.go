@@ -0,0 +1,46 @@
+package renderer
+
+import (
+	"bytes"
+
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KustomizeRender", func() {
+	var dir TestDir
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		dir.MkdirAll("base", 0755)
+		dir.WriteFile("base/kustomization.yaml", []byte("resources:\n- cm.yaml\n"), 0644)
+		dir.WriteFile("base/cm.yaml", []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: base\n"), 0644)
+		dir.MkdirAll("overlay", 0755)
+		dir.WriteFile("overlay/kustomization.yaml", []byte("resources:\n- ../base\nnamePrefix: prod-\ncommonLabels:\n  env: prod\n"), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	It("renders overlays", func() {
+		writer := &bytes.Buffer{}
+		Expect(KustomizeRender(dir.Join("overlay"), nil)(writer)).To(Succeed())
+		Expect(writer.String()).To(ContainSubstring("name: prod-base"))
+		Expect(writer.String()).To(ContainSubstring("env: prod"))
+	})
+
+	It("adds resources", func() {
+		writer := &bytes.Buffer{}
+		resources := []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: helm\n")
+		Expect(KustomizeRender(dir.Join("overlay"), resources)(writer)).To(Succeed())
+		Expect(writer.String()).To(ContainSubstring("name: prod-base"))
+		Expect(writer.String()).To(ContainSubstring("name: prod-helm"))
+	})
+
+	It("reports missing kustomizations", func() {
+		Expect(KustomizeRender(dir.Root(), []byte("kind: Secret"))(&bytes.Buffer{})).To(HaveOccurred())
+		Expect(KustomizeRender(dir.Root(), nil)(&bytes.Buffer{})).To(HaveOccurred())
+	})
+})
@@ -23,6 +23,7 @@ import (
 	"github.com/spf13/pflag"
 	"gopkg.in/yaml.v2"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/k14s/starlark-go/starlark"
 	"github.com/pkg/errors"
 	kdov1a2 "github.com/sap/kubernetes-deployment-orchestrator/api/v1alpha2"
@@ -33,11 +34,19 @@ import (
 	"k8s.io/apimachinery/pkg/selection"
 )
 
+// suffixLabel labels the config map and secret of charts installed with a suffix
+const suffixLabel = "kdo.sap.github.com/suffix"
+
 // RepoListOptions -
 type RepoListOptions struct {
 	allNamespaces bool
 	namespace     string
 	genus         string
+	suffix        string
+	// matchSuffix selects only charts with the given suffix, i.e. charts without suffix if suffix is empty
+	matchSuffix   bool
+	encryptionKey string
+	keyProvider   KeyProvider
 }
 
 // Repo -
@@ -48,11 +57,14 @@ type Repo interface {
 	GetFromSpec(thread *starlark.Thread, spec *kdov1a2.ChartSpec, options ...ChartOption) (ChartValue, error)
 	// List -
 	List(thread *starlark.Thread, k8s k8s.K8s, listOptions *RepoListOptions) ([]ChartValue, error)
+	// GetInstalled returns the chart of the given genus and suffix installed in namespace with the values of the last apply
+	GetInstalled(thread *starlark.Thread, k k8s.K8s, namespace string, genus string, suffix string, options ...ChartOption) (ChartValue, error)
 }
 
 type repoImpl struct {
 	cacheDir string
 	cache    OpenDirCache
+	images   imageConfig
 }
 
 var _ Repo = &repoImpl{}
@@ -87,6 +99,7 @@ func NewRepo(config ...RepoConfig) (Repo, error) {
 	r := &repoImpl{
 		cacheDir: path.Join(homedir, ".kdo", "cache"),
 		cache:    cache,
+		images:   imageConfig{mappings: configs.ImageMappings, lock: configs.ImageLock},
 	}
 	return r, nil
 }
@@ -118,7 +131,14 @@ func openHelm(dirCache *DirCache, client *http.Client, credentials []credential,
 		}
 		if u.Scheme == "helm" {
 			chart := path.Base(u.Path)
+			var constraint *semver.Constraints
+			if version := u.Query().Get("version"); version != "" {
+				if constraint, err = semver.NewConstraint(version); err != nil {
+					return "", err
+				}
+			}
 			u.Path = path.Join(path.Dir(u.Path), "index.yaml")
+			u.RawQuery = ""
 			u.Scheme = "https"
 			dir, err := helmCache(u.String())
 			if err != nil {
@@ -140,15 +160,26 @@ func openHelm(dirCache *DirCache, client *http.Client, credentials []credential,
 				return "", fmt.Errorf("chart %s not found in index", chart)
 			}
 			for _, entry := range entries {
-				if !entry.Deprecated && len(entry.URLs) > 0 {
+				if !entry.Deprecated && len(entry.URLs) > 0 && matchesConstraint(entry.Version, constraint) {
 					return cache(entry.URLs[0])
 				}
 			}
+			if constraint != nil {
+				return "", fmt.Errorf("no version of chart %s matches %s", chart, constraint)
+			}
 		}
 		return cache(uri)
 	}
 }
 
+func matchesConstraint(version string, constraint *semver.Constraints) bool {
+	if constraint == nil {
+		return true
+	}
+	v, err := semver.NewVersion(version)
+	return err == nil && constraint.Check(v)
+}
+
 func openWithFragment(cache OpenDirCache) OpenDirCache {
 	return func(uri string) (string, error) {
 		u, err := url.Parse(uri)
@@ -189,7 +220,7 @@ func (r *repoImpl) Get(thread *starlark.Thread, url string, opts ...ChartOption)
 	if err != nil {
 		return nil, fmt.Errorf("Chart not found for url %s: %s", url, err.Error())
 	}
-	return newChart(thread, r, dir, append(opts, NewGenusAndVersion(url).AsOptions()...)...)
+	return newChart(thread, r, dir, append(append(opts, NewGenusAndVersion(url).AsOptions()...), withConfigImages(r.images))...)
 }
 
 func (r *repoImpl) cacheDirForChart(data []byte) string {
@@ -226,7 +257,7 @@ func (r *repoImpl) GetFromSpec(thread *starlark.Thread, spec *kdov1a2.ChartSpec,
 	return c, nil
 }
 
-func newChartFromConfigMap(thread *starlark.Thread, r *repoImpl, configMap k8s.Object) (ChartValue, error) {
+func newChartFromConfigMap(thread *starlark.Thread, r *repoImpl, configMap k8s.Object, options ...ChartOption) (ChartValue, error) {
 	dataJSON, ok := configMap.Additional["data"]
 	if !ok {
 		return nil, fmt.Errorf("Invalid config map")
@@ -247,7 +278,23 @@ func newChartFromConfigMap(thread *starlark.Thread, r *repoImpl, configMap k8s.O
 		return nil, err
 	}
 	gv := &GenusAndVersion{version: version, genus: configMap.MetaData.Labels["kdo.sap.github.com/genus"]}
-	return newChartFromReader(thread, r, r.cacheDirForChart(tgz), bytes.NewReader(tgz), gv.AsOptions()...)
+	options = append(append(gv.AsOptions(), WithSuffix(configMap.MetaData.Labels[suffixLabel])), append(options, withConfigImages(r.images))...)
+	return newChartFromReader(thread, r, r.cacheDirForChart(tgz), bytes.NewReader(tgz), options...)
+}
+
+func (r *repoImpl) GetInstalled(thread *starlark.Thread, k k8s.K8s, namespace string, genus string, suffix string, options ...ChartOption) (ChartValue, error) {
+	configMap, err := k.Get("configmap", instanceName(genus, suffix), &k8s.Options{Namespace: namespace, IgnoreNotFound: true, Quiet: true})
+	if err != nil {
+		return nil, err
+	}
+	if configMap == nil {
+		if suffix != "" {
+			return nil, fmt.Errorf("chart %s with suffix %s isn't installed in namespace %s", genus, suffix, namespace)
+		}
+		return nil, fmt.Errorf("chart %s isn't installed in namespace %s", genus, namespace)
+	}
+	options = append([]ChartOption{WithNamespace(namespace), WithReuseValues(k)}, options...)
+	return newChartFromConfigMap(thread, r, *configMap, options...)
 }
 
 func (r *repoImpl) List(thread *starlark.Thread, k k8s.K8s, repoListOptions *RepoListOptions) ([]ChartValue, error) {
@@ -266,6 +313,9 @@ func (r *repoImpl) List(thread *starlark.Thread, k k8s.K8s, repoListOptions *Rep
 		}
 		listOptions.LabelSelector = listOptions.LabelSelector.Add(*requirement)
 	}
+	if listOptions.LabelSelector, err = repoListOptions.selectSuffix(listOptions.LabelSelector); err != nil {
+		return nil, err
+	}
 	k8sOptions := &k8s.Options{Quiet: true, Namespace: repoListOptions.namespace, ClusterScoped: repoListOptions.allNamespaces}
 	obj, err := k.List("configmaps", k8sOptions, listOptions)
 	if err != nil {
@@ -277,9 +327,15 @@ func (r *repoImpl) List(thread *starlark.Thread, k k8s.K8s, repoListOptions *Rep
 	if err != nil {
 		return nil, err
 	}
+	provider := repoListOptions.keyProvider
+	if provider == nil && repoListOptions.encryptionKey != "" {
+		if provider, err = NewKeyProvider(repoListOptions.encryptionKey); err != nil {
+			return nil, err
+		}
+	}
 	charts := make([]ChartValue, 0)
 	for _, o := range items {
-		chart, err := newChartFromConfigMap(thread, r, o)
+		chart, err := newChartFromConfigMap(thread, r, o, WithNamespace(o.MetaData.Namespace), withPersistedValues(k), WithEncryption(provider))
 		if err != nil {
 			return nil, err
 		}
@@ -312,6 +368,7 @@ var githubArchive = regexp.MustCompile("https://(github[^/]*/[^/]*/[^/]*)/archiv
 var githubEnterpriseArchive = regexp.MustCompile("https://(github[^/]*)/api/v3/repos/([^/]*/[^/]*)/zipball/(.*)")
 var otherURL = regexp.MustCompile("(https|http)://(.*)/(v{0,1}\\d+\\.\\d+\\.\\d+)")
 var catalogURL = regexp.MustCompile("catalog:(.*)")
+var helmArchive = regexp.MustCompile("([^/]*)-(v{0,1}\\d+\\.\\d+\\.\\d+[^/]*)\\.tgz$")
 
 func loadArchive(name string, targetDir func() (string, error), etagOld string) (string, error) {
 	stat, err := os.Stat(name)
@@ -463,10 +520,30 @@ func zipExtract(in io.Reader, dir string, prefix *regexp.Regexp) error {
 
 }
 
+func (s *RepoListOptions) selectSuffix(selector labels.Selector) (labels.Selector, error) {
+	if s.suffix != "" {
+		requirement, err := labels.NewRequirement(suffixLabel, selection.Equals, []string{s.suffix})
+		if err != nil {
+			return nil, err
+		}
+		return selector.Add(*requirement), nil
+	}
+	if s.matchSuffix {
+		requirement, err := labels.NewRequirement(suffixLabel, selection.DoesNotExist, nil)
+		if err != nil {
+			return nil, err
+		}
+		return selector.Add(*requirement), nil
+	}
+	return selector, nil
+}
+
 // AddFlags -
 func (s *RepoListOptions) AddFlags(flagsSet *pflag.FlagSet) {
 	flagsSet.BoolVarP(&s.allNamespaces, "all-namespaces", "A", false, "List charts in all namespaces")
 	flagsSet.StringVarP(&s.namespace, "namespace", "n", "default", "namespace")
 	flagsSet.StringVarP(&s.genus, "genus", "g", "", "Search for package with the given genus")
+	flagsSet.StringVarP(&s.suffix, "suffix", "s", "", "Search for charts installed with the given suffix")
+	flagsSet.StringVar(&s.encryptionKey, "encryption-key", os.Getenv("KDO_ENCRYPTION_KEY"), "Decrypt the values stored in the cluster with this key")
 
 }
@@ -8,8 +8,10 @@ type credential struct {
 }
 
 type repoConfigs struct {
-	Credentials []credential `yaml:"credentials,omitempty"`
-	Catalogs    []string     `yaml:"catalogs,omitempty"`
+	Credentials   []credential   `yaml:"credentials,omitempty"`
+	Catalogs      []string       `yaml:"catalogs,omitempty"`
+	ImageMappings []ImageMapping `yaml:"imageMappings,omitempty"`
+	ImageLock     string         `yaml:"imageLock,omitempty"`
 }
 
 // RepoConfig -
@@ -54,4 +54,21 @@ credentials:
 		Expect(c.Credentials[0].Password).To(Equal("password"))
 	})
 
+	It("image mappings work", func() {
+		c := &repoConfigs{}
+		dir := NewTestDir()
+		defer dir.Remove()
+		dir.WriteFile("config.yaml", []byte(`
+imageMappings:
+  - from: docker.io/
+    to: registry.local/mirror/
+imageLock: images.lock
+`), 0644)
+
+		err := WithConfigFile(dir.Join("config.yaml"))(c)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.ImageMappings).To(Equal([]ImageMapping{{From: "docker.io/", To: "registry.local/mirror/"}}))
+		Expect(c.ImageLock).To(Equal("images.lock"))
+	})
+
 })
@@ -0,0 +1,188 @@
+package kdo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/starutils"
+	"gopkg.in/yaml.v2"
+)
+
+// explicitValuesAnnotation lists the names of the values of the secret of a chart, which were given explicitly or
+// passed to init. The other values were assigned by the chart itself.
+const explicitValuesAnnotation = "kdo.sap.github.com/explicit-values"
+
+// persistedValues returns the values stored in the secret of an applied chart or nil if the secret doesn't exist.
+// With explicitOnly, values assigned by the chart itself are omitted. Secrets written without the annotation keep
+// all values.
+func persistedValues(k k8s.K8sReader, namespace string, name string, provider KeyProvider, explicitOnly bool) (map[string]interface{}, error) {
+	obj, err := k.Get("secret", name, &k8s.Options{Namespace: namespace, IgnoreNotFound: true, Quiet: true})
+	if err != nil || obj == nil {
+		return nil, err
+	}
+	data, err := secretValues(obj, provider)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data of secret %s: %w", name, err)
+	}
+	if explicit, ok := obj.MetaData.Annotations[explicitValuesAnnotation]; ok && explicitOnly {
+		keys := map[string]bool{}
+		for _, key := range strings.Split(explicit, ",") {
+			keys[key] = true
+		}
+		for key := range data {
+			if !keys[key] {
+				delete(data, key)
+			}
+		}
+	}
+	values := make(map[string]interface{}, len(data))
+	for key, raw := range data {
+		// values are stored as json, yaml keeps integers
+		var value interface{}
+		if err := yaml.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("invalid value %s of secret %s: %w", key, name, err)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// secretValues returns the json encoded values stored in the secret of a chart. Encrypted values are decrypted.
+func secretValues(obj *k8s.Object, provider KeyProvider) (map[string][]byte, error) {
+	data := map[string][]byte{}
+	if raw, ok := obj.Additional["data"]; ok {
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, err
+		}
+	}
+	encrypted, ok := data[encryptedValuesKey]
+	if !ok {
+		return data, nil
+	}
+	plaintext, err := decryptValues(provider, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	data = map[string][]byte{}
+	if err := json.Unmarshal(plaintext, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// isEncrypted returns true if the secret of a chart holds encrypted values
+func isEncrypted(obj *k8s.Object) bool {
+	data := map[string]json.RawMessage{}
+	if raw, ok := obj.Additional["data"]; ok {
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return false
+		}
+	}
+	_, ok := data[encryptedValuesKey]
+	return ok
+}
+
+// checkEncryption refuses to replace the encrypted values of the secret of the chart with unencrypted values,
+// unless this is allowed explicitly
+func (c *chartImpl) checkEncryption(obj *k8s.Object) error {
+	if obj == nil || !isEncrypted(obj) || c.allowUnencrypted {
+		return nil
+	}
+	provider, err := c.encryption()
+	if err != nil || provider != nil {
+		return err
+	}
+	return fmt.Errorf("values in secret %s are encrypted, use --encryption-key or --allow-unencrypted to store them unencrypted", c.objName())
+}
+
+// setSecretValues stores the json encoded values in the secret of a chart. Values are encrypted if provider isn't nil.
+func setSecretValues(obj *k8s.Object, values map[string][]byte, provider KeyProvider) error {
+	if provider != nil {
+		plaintext, err := json.Marshal(values)
+		if err != nil {
+			return err
+		}
+		encrypted, err := encryptValues(provider, plaintext)
+		if err != nil {
+			return err
+		}
+		values = map[string][]byte{encryptedValuesKey: encrypted}
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	obj.Additional = map[string]json.RawMessage{
+		"data": data,
+	}
+	return nil
+}
+
+// reusePersistedValues adds the values of the previous apply to the options, unless they are given explicitly.
+// Subcharts get their values from the persisted values of their parent, therefore reuse isn't passed to them.
+func (c *chartImpl) reusePersistedValues(co *ChartOptions) error {
+	k := co.reuseFrom
+	co.reuseFrom = nil
+	c.reuseFrom = nil
+	provider, err := co.encryption()
+	if err != nil {
+		return err
+	}
+	values, err := persistedValues(k, co.namespace, c.objName(), provider, !co.reuseAll)
+	if err != nil {
+		// listing charts doesn't require the encryption key, the charts keep their default values
+		if co.reuseQuiet && errors.Is(err, errEncryptionKeyRequired) {
+			return nil
+		}
+		return err
+	}
+	source := "reused from secret " + c.objName()
+	properties := Properties{}
+	for _, item := range co.properties.GetValue().(*starlark.Dict).Items() {
+		key := item.Index(0).(starlark.String).GoString()
+		properties.set(key, item.Index(1), co.properties.source(key))
+	}
+	reused := []string{}
+	for key, value := range values {
+		if properties.get(key) != starlark.None {
+			continue
+		}
+		properties.set(key, starutils.ToStarlark(value), source)
+		reused = append(reused, key)
+	}
+	co.properties = properties
+	c.properties = properties
+	c.reused = reused
+	return nil
+}
+
+// dropUndeclaredValues removes the reused values, which are neither properties nor arguments of init of this
+// chart version anymore
+func (c *chartImpl) dropUndeclaredValues(co *ChartOptions) {
+	if c.reused == nil {
+		return
+	}
+	initArgs := map[string]bool{}
+	for _, kwarg := range c.initKwargs {
+		initArgs[kwarg.Index(0).(starlark.String).GoString()] = true
+	}
+	reused := []string{}
+	for _, key := range c.reused {
+		if _, ok := c.values[key].(Property); ok || initArgs[key] {
+			reused = append(reused, key)
+		} else {
+			co.properties.delete(key)
+		}
+	}
+	c.reused = reused
+	if len(reused) != 0 && !co.reuseQuiet {
+		sort.Strings(reused)
+		fmt.Fprintf(os.Stderr, "Reusing values from secret %s in namespace %s: %s\n", c.objName(), co.namespace, strings.Join(reused, ", "))
+	}
+}
@@ -0,0 +1,100 @@
+package kdo
+
+import (
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("Reuse values", func() {
+	var dir TestDir
+	var k *k8s.K8sInMemory
+	thread := &starlark.Thread{Name: "main"}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		k = k8s.NewK8sInMemory("test")
+		dir.WriteFile("Chart.yaml", []byte("name: test\nversion: 1.0.0\n"), 0644)
+		dir.WriteFile("Chart.star", []byte(`
+def init(self, replicas = 1):
+	self.replicas = property(default = replicas)
+	self.message = property(default = "hello")
+	self.tags = property(default = ["a"])
+`), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	newTestChart := func(options ...ChartOption) *chartImpl {
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), append([]ChartOption{WithNamespace("test")}, options...)...)
+		Expect(err).NotTo(HaveOccurred())
+		return c
+	}
+
+	value := func(c *chartImpl, name string) interface{} {
+		v, _ := c.Attr(name)
+		return v
+	}
+
+	It("merges persisted values under explicit values", func() {
+		c := newTestChart(WithValues(map[string]interface{}{"message": "persisted", "replicas": 3, "tags": []interface{}{"x", "y"}}))
+		Expect(c.Apply(thread, k)).To(Succeed())
+
+		c = newTestChart()
+		Expect(value(c, "message")).To(Equal(starlark.String("hello")))
+
+		c = newTestChart(WithReuseValues(k), WithValues(map[string]interface{}{"message": "explicit"}))
+		Expect(value(c, "message")).To(Equal(starlark.String("explicit")))
+		Expect(value(c, "replicas")).To(Equal(starlark.MakeInt(3)))
+		Expect(value(c, "tags").(starlark.Indexable).Len()).To(Equal(2))
+		Expect(c.source("tags")).To(Equal("reused from secret kdo.test"))
+		Expect(c.source("message")).To(Equal("values"))
+	})
+
+	It("reuses only explicit values, which are still declared", func() {
+		dir.WriteFile("Chart.star", []byte(`
+def init(self):
+	self.message = property(default = "hello")
+	self.obsolete = property()
+	self.workers = property(default = 1)
+	self.workers = 2
+`), 0644)
+		c := newTestChart(WithValues(map[string]interface{}{"message": "persisted", "obsolete": "x"}))
+		Expect(c.Apply(thread, k)).To(Succeed())
+		secret, err := k.Get("secret", "kdo.test", &k8s.Options{Namespace: "test"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secret.MetaData.Annotations).To(HaveKeyWithValue(explicitValuesAnnotation, "message,obsolete"))
+		Expect(persistedValues(k, "test", "kdo.test", nil, false)).To(HaveKeyWithValue("workers", 2))
+		Expect(persistedValues(k, "test", "kdo.test", nil, true)).NotTo(HaveKey("workers"))
+
+		dir.WriteFile("Chart.star", []byte(`
+def init(self):
+	self.message = property(default = "hello")
+	self.workers = property(default = 1)
+	self.workers = 3
+`), 0644)
+		c = newTestChart(WithReuseValues(k))
+		Expect(value(c, "message")).To(Equal(starlark.String("persisted")))
+		Expect(value(c, "workers")).To(Equal(starlark.MakeInt(3)))
+		Expect(c.values).NotTo(HaveKey("obsolete"))
+		Expect(c.reused).To(Equal([]string{"message"}))
+	})
+
+	It("persists the values assigned to subcharts by their parent", func() {
+		dir.MkdirAll("sub", 0755)
+		dir.WriteFile("sub/Chart.star", []byte("def init(self):\n\tself.replicas = property(default = 1)\n"), 0644)
+		dir.WriteFile("Chart.star", []byte("def init(self):\n\tself.sub = chart(\"sub\")\n\tself.sub.replicas = 3\n"), 0644)
+		c := newTestChart()
+		Expect(c.Apply(thread, k)).To(Succeed())
+		Expect(persistedValues(k, "test", "kdo.sub", nil, false)).To(HaveKeyWithValue("replicas", 3))
+	})
+
+	It("ignores charts which aren't installed", func() {
+		c := newTestChart(WithReuseValues(k))
+		Expect(value(c, "message")).To(Equal(starlark.String("hello")))
+	})
+})
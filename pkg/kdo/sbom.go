@@ -0,0 +1,224 @@
+package kdo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type cycloneDXBom struct {
+	BomFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber"`
+	Version      int                   `json:"version"`
+	Metadata     cycloneDXMetadata     `json:"metadata"`
+	Components   []cycloneDXComponent  `json:"components"`
+	Dependencies []cycloneDXDependency `json:"dependencies"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     []cycloneDXTool    `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type cycloneDXComponent struct {
+	BomRef             string                 `json:"bom-ref"`
+	Type               string                 `json:"type"`
+	Name               string                 `json:"name"`
+	Version            string                 `json:"version,omitempty"`
+	Hashes             []cycloneDXHash        `json:"hashes,omitempty"`
+	ExternalReferences []cycloneDXExternalRef `json:"externalReferences,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cycloneDXExternalRef struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// CycloneDX returns the inventory as CycloneDX 1.4 bill of materials. Charts are components of type application,
+// images are components of type container.
+func (i *ChartInventory) CycloneDX() interface{} {
+	bom := &cycloneDXBom{
+		BomFormat:    "CycloneDX",
+		SpecVersion:  "1.4",
+		SerialNumber: "urn:uuid:" + uuid.New().String(),
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools:     []cycloneDXTool{{Name: "kdo", Version: Version()}},
+		},
+		Components:   []cycloneDXComponent{},
+		Dependencies: []cycloneDXDependency{},
+	}
+	refs := i.refs()
+	images := map[string]bool{}
+	i.walk(func(inventory *ChartInventory, parent *ChartInventory) {
+		component := cycloneDXComponent{BomRef: refs[inventory], Type: "application", Name: inventory.Name, Version: inventory.Version}
+		if isRemoteURL(inventory.URL) {
+			component.ExternalReferences = []cycloneDXExternalRef{{Type: "distribution", URL: inventory.URL}}
+		}
+		if parent == nil {
+			bom.Metadata.Component = component
+		} else {
+			bom.Components = append(bom.Components, component)
+		}
+		dependency := cycloneDXDependency{Ref: refs[inventory]}
+		for _, subChart := range inventory.Subcharts {
+			dependency.DependsOn = append(dependency.DependsOn, refs[subChart])
+		}
+		for _, image := range inventory.uniqueImages() {
+			ref := "image:" + image
+			dependency.DependsOn = append(dependency.DependsOn, ref)
+			if images[image] {
+				continue
+			}
+			images[image] = true
+			parsed := parseImage(image)
+			component := cycloneDXComponent{BomRef: ref, Type: "container", Name: parsed.name, Version: parsed.tag}
+			if strings.HasPrefix(parsed.digest, "sha256:") {
+				component.Hashes = []cycloneDXHash{{Alg: "SHA-256", Content: strings.TrimPrefix(parsed.digest, "sha256:")}}
+				if component.Version == "" {
+					component.Version = parsed.digest
+				}
+			}
+			bom.Components = append(bom.Components, component)
+		}
+		bom.Dependencies = append(bom.Dependencies, dependency)
+	})
+	return bom
+}
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string `json:"name"`
+	SPDXID           string `json:"SPDXID"`
+	VersionInfo      string `json:"versionInfo,omitempty"`
+	DownloadLocation string `json:"downloadLocation"`
+	FilesAnalyzed    bool   `json:"filesAnalyzed"`
+	Comment          string `json:"comment,omitempty"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+const spdxNoAssertion = "NOASSERTION"
+
+// SPDX returns the inventory as SPDX 2.3 document. Subcharts are contained in their parent, charts depend on the
+// images of their workloads.
+func (i *ChartInventory) SPDX() interface{} {
+	doc := &spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              i.Name,
+		DocumentNamespace: fmt.Sprintf("https://github.com/sap/kubernetes-deployment-orchestrator/spdx/%s-%s", i.Name, uuid.New().String()),
+		CreationInfo: spdxCreationInfo{
+			Created:  time.Now().UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: kdo-" + Version()},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+	charts := map[*ChartInventory]string{}
+	images := map[string]string{}
+	i.walk(func(inventory *ChartInventory, parent *ChartInventory) {
+		id := fmt.Sprintf("SPDXRef-Chart-%d", len(charts)+1)
+		charts[inventory] = id
+		location := spdxNoAssertion
+		if isRemoteURL(inventory.URL) {
+			location = inventory.URL
+		}
+		doc.Packages = append(doc.Packages, spdxPackage{Name: inventory.Name, SPDXID: id, VersionInfo: inventory.Version, DownloadLocation: location, Comment: "kdo chart"})
+		for _, subChart := range inventory.Subcharts {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: id, RelationshipType: "CONTAINS", RelatedSPDXElement: charts[subChart]})
+		}
+		for _, image := range inventory.uniqueImages() {
+			imageID, ok := images[image]
+			if !ok {
+				imageID = fmt.Sprintf("SPDXRef-Image-%d", len(images)+1)
+				images[image] = imageID
+				parsed := parseImage(image)
+				version := parsed.tag
+				if parsed.digest != "" && version != "" {
+					version += "@" + parsed.digest
+				} else if parsed.digest != "" {
+					version = parsed.digest
+				}
+				doc.Packages = append(doc.Packages, spdxPackage{Name: parsed.name, SPDXID: imageID, VersionInfo: version, DownloadLocation: spdxNoAssertion, Comment: "container image " + image})
+			}
+			doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: id, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: imageID})
+		}
+		if parent == nil {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: doc.SPDXID, RelationshipType: "DESCRIBES", RelatedSPDXElement: id})
+		}
+	})
+	return doc
+}
+
+// isRemoteURL returns false for charts loaded from local directories
+func isRemoteURL(url string) bool {
+	return strings.Contains(url, "://")
+}
+
+// refs returns unique references of the charts of the inventory built from the names of their parents
+func (i *ChartInventory) refs() map[*ChartInventory]string {
+	result := map[*ChartInventory]string{}
+	var refs func(inventory *ChartInventory, prefix string)
+	refs = func(inventory *ChartInventory, prefix string) {
+		result[inventory] = "chart:" + prefix + inventory.Name
+		for _, subChart := range inventory.Subcharts {
+			refs(subChart, prefix+inventory.Name+"/")
+		}
+	}
+	refs(i, "")
+	return result
+}
+
+// uniqueImages returns the images used by the chart without duplicates
+func (i *ChartInventory) uniqueImages() []string {
+	result := []string{}
+	found := map[string]bool{}
+	for _, image := range i.Images {
+		if image.Image != "" && !found[image.Image] {
+			found[image.Image] = true
+			result = append(result, image.Image)
+		}
+	}
+	return result
+}
@@ -95,16 +95,26 @@ func (s *structProperty) SetValue(value starlark.Value) error {
 	if !ok {
 		return fmt.Errorf("value must be a dict not %s", value.String())
 	}
+	errs := &ValidationError{}
 	for _, t := range m.Items() {
 		key, ok := t.Index(0).(starlark.String)
 		if ok {
 			err := s.SetField(key.GoString(), t.Index(1))
 			if err != nil {
-				return err
+				errs.add("", err)
 			}
 		}
 	}
-	return nil
+	return errs.orNil()
+}
+
+// validate reports required properties without value
+func (s *structProperty) validate() error {
+	values := make(map[string]starlark.Value, len(s.properties))
+	for name, property := range s.properties {
+		values[name] = property
+	}
+	return validateValues(values)
 }
 
 func (s *structProperty) getValue(getter func(ReadProperty) starlark.Value) *starlark.Dict {
@@ -141,11 +151,7 @@ func (s *structProperty) SetField(name string, val starlark.Value) error {
 		}
 		s.properties[name] = property
 	}
-	err := property.SetValue(val)
-	if err != nil {
-		return err
-	}
-	return nil
+	return validationError(name, property.SetValue(val))
 }
 
 func (s *structProperty) SetKey(k, v starlark.Value) error {
@@ -0,0 +1,106 @@
+package kdo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k14s/starlark-go/starlark"
+)
+
+// ValidationIssue is a single problem found while validating the value of a property
+type ValidationIssue struct {
+	Path    string
+	Message string
+	// Source tells where the invalid value was set, e.g. values.yaml or --set replicas
+	Source string
+}
+
+func (i ValidationIssue) String() string {
+	message := i.Message
+	if i.Source != "" {
+		message += " (set by " + i.Source + ")"
+	}
+	if i.Path == "" {
+		return message
+	}
+	return i.Path + ": " + message
+}
+
+// ValidationError collects all issues found while setting or validating properties
+type ValidationError struct {
+	Issues []ValidationIssue
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Issues) == 1 {
+		return e.Issues[0].String()
+	}
+	lines := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		lines = append(lines, "  - "+issue.String())
+	}
+	return fmt.Sprintf("%d validation errors:\n%s", len(e.Issues), strings.Join(lines, "\n"))
+}
+
+// add appends the issues of err. The paths of the issues are prefixed with path.
+func (e *ValidationError) add(path string, err error) {
+	v, ok := err.(*ValidationError)
+	if !ok {
+		e.Issues = append(e.Issues, ValidationIssue{Path: path, Message: err.Error()})
+		return
+	}
+	for _, issue := range v.Issues {
+		switch {
+		case path == "":
+		case issue.Path == "":
+			issue.Path = path
+		default:
+			issue.Path = path + "." + issue.Path
+		}
+		e.Issues = append(e.Issues, issue)
+	}
+}
+
+// orNil returns nil if no issues were found
+func (e *ValidationError) orNil() error {
+	if len(e.Issues) == 0 {
+		return nil
+	}
+	return e
+}
+
+// validationError returns err as ValidationError prefixed with path
+func validationError(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(starlark.NoSuchAttrError); ok {
+		return err
+	}
+	result := &ValidationError{}
+	result.add(path, err)
+	return result
+}
+
+type validatable interface {
+	validate() error
+}
+
+// validateValues validates all values which are properties or subcharts
+func validateValues(values map[string]starlark.Value) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := &ValidationError{}
+	for _, name := range names {
+		if v, ok := values[name].(validatable); ok {
+			if err := v.validate(); err != nil {
+				result.add(name, err)
+			}
+		}
+	}
+	return result.orNil()
+}
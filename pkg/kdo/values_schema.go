@@ -0,0 +1,110 @@
+package kdo
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/k14s/starlark-go/starlark"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/starutils"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+const valuesSchemaFile = "values.schema.json"
+
+// validateSchema validates the merged values of the chart against values.schema.json, if the chart contains one
+func (c *chartImpl) validateSchema() error {
+	file := c.path(valuesSchemaFile)
+	if _, err := os.Stat(file); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewReferenceLoader("file://" + file))
+	if err != nil {
+		return validationError(valuesSchemaFile, err)
+	}
+	result, err := schema.Validate(gojsonschema.NewGoLoader(schemaValues(c)))
+	if err != nil {
+		return validationError(valuesSchemaFile, err)
+	}
+	errs := &ValidationError{}
+	for _, e := range result.Errors() {
+		path := e.Field()
+		if e.Type() == "required" {
+			if property, ok := e.Details()["property"].(string); ok {
+				path = strings.TrimPrefix(path+"."+property, "(root).")
+			}
+		}
+		if path == "(root)" {
+			path = ""
+		}
+		errs.Issues = append(errs.Issues, ValidationIssue{Path: path, Message: describeSchemaError(e), Source: c.source(path)})
+	}
+	return errs.orNil()
+}
+
+// describeSchemaError formats the description of e. Unlike e.Description() numbers aren't printed as fractions.
+func describeSchemaError(e gojsonschema.ResultError) string {
+	description := e.DescriptionFormat()
+	for key, value := range e.Details() {
+		if r, ok := value.(*big.Rat); ok {
+			value = r.RatString()
+		}
+		description = strings.ReplaceAll(description, "{{."+key+"}}", fmt.Sprint(value))
+	}
+	if strings.Contains(description, "{{") {
+		return e.Description()
+	}
+	return description
+}
+
+// source returns where the value with the given path was set
+func (c *chartImpl) source(path string) string {
+	if path == "" {
+		return ""
+	}
+	key := strings.SplitN(path, ".", 2)[0]
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	if _, ok := c.values[key]; ok {
+		return "Chart.star"
+	}
+	return ""
+}
+
+// schemaValues returns the values as seen by templates including false and zero values. Unset values are omitted.
+func schemaValues(value starlark.Value) interface{} {
+	switch v := value.(type) {
+	case *chartImpl:
+		return schemaValueMap(v.values)
+	case *structProperty:
+		values := make(map[string]starlark.Value, len(v.properties))
+		for name, p := range v.properties {
+			values[name] = p
+		}
+		return schemaValueMap(values)
+	case ReadProperty:
+		value = v.GetValueOrDefault()
+	}
+	if value == starlark.None {
+		return nil
+	}
+	return starutils.ToGo(value)
+}
+
+func schemaValueMap(values map[string]starlark.Value) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		if _, ok := value.(ReadProperty); !ok && inferType(value) == "" {
+			continue
+		}
+		if v := schemaValues(value); v != nil {
+			result[name] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package kdo
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/k14s/starlark-go/starlark"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sap/kubernetes-deployment-orchestrator/pkg/k8s"
+	. "github.com/sap/kubernetes-deployment-orchestrator/pkg/kdo/test"
+)
+
+var _ = Describe("values.schema.json", func() {
+	var dir TestDir
+	thread := &starlark.Thread{Name: "main"}
+
+	BeforeEach(func() {
+		dir = NewTestDir()
+		dir.WriteFile("values.yaml", []byte("replicas: 1\nimage:\n  tag: latest\nenabled: false\n"), 0644)
+		dir.WriteFile("values.schema.json", []byte(`{
+  "type": "object",
+  "required": ["replicas", "enabled", "name"],
+  "properties": {
+    "replicas": { "type": "integer", "minimum": 1 },
+    "enabled": { "type": "boolean" },
+    "image": {
+      "type": "object",
+      "properties": { "tag": { "type": "string", "pattern": "^v" } }
+    }
+  }
+}`), 0644)
+	})
+	AfterEach(func() {
+		dir.Remove()
+	})
+
+	It("reports invalid values with their source", func() {
+		repo, _ := NewRepo()
+		options := ChartOptions{}
+		Expect(options.properties.Set("replicas=0")).To(Succeed())
+		c, err := newChart(thread, repo, dir.Root(), options.Merge())
+		Expect(err).NotTo(HaveOccurred())
+		err = c.Template(thread, k8s.NewK8sInMemory("test"))(&bytes.Buffer{})
+		Expect(err).To(HaveOccurred())
+		validationError := &ValidationError{}
+		Expect(errors.As(err, &validationError)).To(BeTrue())
+		Expect(validationError.Issues).To(ConsistOf(
+			ValidationIssue{Path: "name", Message: "name is required"},
+			ValidationIssue{Path: "replicas", Message: "Must be greater than or equal to 1", Source: "--set replicas"},
+			ValidationIssue{Path: "image.tag", Message: "Does not match pattern '^v'", Source: "values.yaml"},
+		))
+		Expect(err.Error()).To(ContainSubstring("replicas: Must be greater than or equal to 1 (set by --set replicas)"))
+	})
+
+	It("accepts valid values", func() {
+		repo, _ := NewRepo()
+		c, err := newChart(thread, repo, dir.Root(), WithValues(map[string]interface{}{"name": "test", "image": map[string]interface{}{"tag": "v1"}}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.validate()).To(Succeed())
+	})
+})